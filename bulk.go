@@ -0,0 +1,330 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cxr29/huge/query"
+)
+
+// maxParameters of a bulk statement, the least of the dialects.
+const maxParameters = 999
+
+// UpdateRows []T returns map[int]struct{}, map[]T returns map[]struct{} of the rows updated,
+// each with its own values, by one statement per chunk with version checked per row,
+// UPDATE ... FROM (VALUES ...) on PostgreSQL, else SET c = CASE pk WHEN ... END,
+// the rows updated are by RETURNING if supported, else by the rows affected,
+// but row by row like Update if versioned, a short chunk can not tell the conflicts.
+func (h Huge) UpdateRows(i interface{}, columns ...string) (interface{}, error) {
+	t := NewTable(i)
+	v, _ := ptrElem(i)
+	if t.PrimaryKey() == nil {
+		panic(t.errNoPrimaryKey())
+	}
+	a := t.updateFilter(columns...)
+	if a.Empty() {
+		return nil, t.errNoColumns()
+	} else if err := h.checkVersion(t); err != nil {
+		return nil, err
+	}
+	output, returning, err := h.returning('u', Columns{t.PrimaryKey()})
+	if err != nil {
+		return nil, err
+	}
+	var keys, rows []reflect.Value
+	switch v.Kind() {
+	case reflect.Map:
+		keys = v.MapKeys()
+		rows = make([]reflect.Value, len(keys))
+		for k, j := range keys {
+			rows[k] = v.MapIndex(j)
+		}
+	case reflect.Slice:
+		rows = make([]reflect.Value, v.Len())
+		for k := range rows {
+			rows[k] = v.Index(k)
+		}
+	default:
+		panic("huge: type unsupported")
+	}
+	n := 0
+	for _, c := range a {
+		if !c.isAutoNow() && !c.isVersion() {
+			n++
+		}
+	}
+	postgres := h.Starter.Dialect() == "postgres"
+	if postgres {
+		n++
+	} else {
+		n = n*2 + 1
+	}
+	if t.Version() != nil {
+		n++
+	}
+	x := maxParameters
+	if t.AutoNow() != nil {
+		x-- // once per statement
+	}
+	if n = x / n; n < 1 {
+		n = 1
+	}
+	now := h.now()
+	b := make([]bool, len(rows))
+	if t.Version() != nil && len(output) == 0 && len(returning) == 0 {
+		s := make([]*stmt, 2)
+		defer func() {
+			for _, j := range s {
+				if j != nil {
+					h.logWarning(j.Close())
+				}
+			}
+		}()
+		for k := 0; k < len(rows) && err == nil; k++ {
+			b[k], err = h.update1("", nil, s, t, a, false, rows[k], now)
+		}
+	} else {
+		for j := 0; j < len(rows) && err == nil; j += n {
+			k := j + n
+			if k > len(rows) {
+				k = len(rows)
+			}
+			err = h.updateRows(postgres, output+returning, t, a, rows[j:k], b[j:k], now)
+		}
+	}
+	if keys != nil {
+		m := reflect.MakeMap(reflect.MapOf(v.Type().Key(), typeEmpty))
+		for k, j := range keys {
+			if b[k] {
+				m.SetMapIndex(j, zeroEmpty)
+			}
+		}
+		return m.Interface(), err
+	}
+	m := make(map[int]struct{}, len(rows))
+	for k, ok := range b {
+		if ok {
+			m[k] = struct{}{}
+		}
+	}
+	return m, err
+}
+
+// updateRows of a chunk, b is set for the rows updated, by returning the primary keys if not empty.
+func (h Huge) updateRows(postgres bool, returning string, t *Table, a Columns, rows []reflect.Value, b []bool, now time.Time) error {
+	pk, ver := t.PrimaryKey(), t.Version()
+	var d Columns
+	for _, c := range a {
+		if !c.isAutoNow() && !c.isVersion() {
+			d = append(d, c)
+		}
+	}
+	p := make([][]interface{}, len(rows))
+	x := make([]int64, len(rows))
+	e := make([][]interface{}, len(rows))
+	for k, v := range rows {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return t.errNil()
+			}
+			v = v.Elem()
+			rows[k] = v
+		}
+		var err error
+		if p[k], x[k], err = t.getPrimaryKeyVersion(v); err != nil {
+			return err
+		}
+		e[k] = make([]interface{}, len(d))
+		for j, c := range d {
+			if e[k][j], err = c.get(v); err != nil {
+				return err
+			}
+		}
+	}
+	update, set := h.updateSet(t.Name)
+	var where query.Expression
+	var from query.Expression
+	if postgres {
+		names := []interface{}{query.Identifier("huge_v"), query.Identifier("huge_0")}
+		cast := []string{pk.castType(h.Starter)}
+		if ver != nil {
+			names = append(names, query.Identifier("huge_1"))
+			cast = append(cast, ver.castType(h.Starter))
+		}
+		for j, c := range d {
+			s := fmt.Sprintf("huge_%d", len(names)-1)
+			names = append(names, query.Identifier(s))
+			cast = append(cast, c.castType(h.Starter))
+			set.Add(d[j].Name, query.Qualifier{"huge_v", s})
+		}
+		var s []string
+		var args []interface{}
+		for k := range rows {
+			f := make([]string, 0, len(cast))
+			for j := range cast {
+				if k == 0 && len(cast[j]) > 0 {
+					f = append(f, "CAST(? AS "+cast[j]+")")
+				} else {
+					f = append(f, "?")
+				}
+			}
+			s = append(s, "("+strings.Join(f, ", ")+")")
+			args = append(args, p[k]...)
+			if ver != nil && len(p[k]) == 1 {
+				args = append(args, nil)
+			}
+			args = append(args, e[k]...)
+		}
+		args = append(args, names...)
+		from = query.E("FROM (VALUES "+strings.Join(s, ", ")+") AS ?("+strings.Repeat(", ?", len(names)-1)[2:]+")", args...)
+		c := query.C("? = ?", query.Identifier(pk.Name), query.Qualifier{"huge_v", "huge_0"})
+		if ver != nil {
+			c = c.And(query.C("? IS NULL OR ? = ?", query.Qualifier{"huge_v", "huge_1"}, query.Identifier(ver.Name), query.Qualifier{"huge_v", "huge_1"}))
+		}
+		where = query.Where(c)
+	} else {
+		for j, c := range d {
+			args := make([]interface{}, 1, len(rows)*2+1)
+			args[0] = query.Identifier(pk.Name)
+			for k := range rows {
+				args = append(args, p[k][0], e[k][j])
+			}
+			set.Add(c.Name, query.E("CASE ?"+strings.Repeat(" WHEN ? THEN ?", len(rows))+" END", args...))
+		}
+		if ver != nil {
+			c := make([]query.Condition, len(rows))
+			for k := range rows {
+				if c[k] = pk.Eq(p[k][0]); len(p[k]) == 2 {
+					c[k] = c[k].And(ver.Eq(p[k][1]))
+				}
+			}
+			where = query.Where(query.Or(c...))
+		} else {
+			c := make([]interface{}, len(rows))
+			for k := range rows {
+				c[k] = p[k][0]
+			}
+			where = query.Where(pk.In(c...))
+		}
+	}
+	if c := t.AutoNow(); c != nil {
+		i := c.convertTime(now, h.TimePrec)
+		if i == nil {
+			return c.errSet()
+		}
+		set.Add(c.Name, i)
+	}
+	if ver != nil {
+		set.Add(ver.Name, ver.Inc())
+	}
+	q := query.Q(update, set)
+	_, output := h.Starter.(query.Outputer)
+	if output && len(returning) > 0 {
+		q.Append(query.Literal(returning))
+	}
+	if from != nil {
+		q.Append(from)
+	}
+	q.Append(where)
+	if !output && len(returning) > 0 {
+		q.Append(query.Literal(returning))
+	}
+	if len(returning) > 0 {
+		if err := h.updatedRows(t, q, p, b); err != nil {
+			return err
+		}
+	} else {
+		r, err := h.exec(t.Name, q)
+		if err != nil {
+			return err
+		}
+		n, err := h.rowsAffected(r, int64(len(rows)))
+		if err != nil {
+			return err
+		}
+		if n == int64(len(rows)) {
+			for k := range b {
+				b[k] = true
+			}
+		} else {
+			c := make([]interface{}, len(p))
+			for k := range p {
+				c[k] = p[k][0]
+			}
+			q = query.Q(query.Select(pk.Name), query.From(t.Name), query.Where(pk.In(c...)))
+			if err = h.Primary().updatedRows(t, q, p, b); err != nil {
+				return err
+			}
+		}
+	}
+	for k, v := range rows {
+		if !b[k] {
+			continue
+		}
+		if c := ver; x[k] > 0 && !c.setInteger(v, x[k]+1) {
+			return c.errSet()
+		}
+		if c := t.AutoNow(); c != nil && !c.setTime(v, now, h.TimePrec) {
+			return c.errSet()
+		}
+		t.takeSnapshot(v, a)
+	}
+	return nil
+}
+
+// updatedRows of the primary keys p by the primary keys q returns,
+// the updated by RETURNING, or the existing if a chunk without version is short.
+func (h Huge) updatedRows(t *Table, q query.Expression, p [][]interface{}, b []bool) error {
+	s, args, err := h.Expand(q)
+	if err != nil {
+		return err
+	}
+	rows, err := h.query(t.Name, s, args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	a := Columns{t.PrimaryKey()}
+	m := make(map[string]struct{}, len(p))
+	v := reflect.New(t.s.t).Elem()
+	for rows.Next() {
+		d, f, err := scanColumns(a, v, h.Location)
+		if err != nil {
+			return err
+		} else if err = rows.Scan(d...); err != nil {
+			return err
+		} else if err = f(); err != nil {
+			return err
+		}
+		i, err := a[0].get(v)
+		if err != nil {
+			return err
+		}
+		m[fmt.Sprint(i)] = struct{}{}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for k := range p {
+		_, b[k] = m[fmt.Sprint(p[k][0])]
+	}
+	return nil
+}
+
+// castType of c for VALUES on the Starter, empty if unknown.
+func (c *Column) castType(s query.Starter) string {
+	f := c.last()
+	if f.x != nil && !f.IsEncoding() {
+		if x, ok := f.x.DDL[s.Dialect()]; ok {
+			return x
+		}
+	}
+	x, _ := s.Mapping(c.Name, f.typeName(), -1, query.OptionZeroValue) // unlimited, not truncated by the cast
+	return x
+}
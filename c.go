@@ -5,13 +5,11 @@
 package huge
 
 import (
-	"database/sql"
 	"fmt"
 	"reflect"
 	"time"
 
 	"github.com/cxr29/huge/query"
-	"github.com/cxr29/log"
 )
 
 // Upsert if z is true, PK = 0 Create, PK > 0 Update, PK < 0 noop;
@@ -39,7 +37,7 @@ func (h Huge) Upsert(z bool, i interface{}, columns ...string) (ok bool, err err
 		} else {
 			z = j > 0
 		}
-	} else if j, k := h.Read(i, c.Name); k != nil {
+	} else if j, k := h.Primary().Read(i, c.Name); k != nil {
 		return false, k
 	} else {
 		z = j.(bool)
@@ -59,43 +57,59 @@ func (h Huge) Upsert(z bool, i interface{}, columns ...string) (ok bool, err err
 func (h Huge) Create(i interface{}) (interface{}, error) {
 	t := NewTable(i)
 	v, _ := ptrElem(i)
-	values := query.X.Values()
-	for _, c := range t.a {
-		if c.isMany() || c.isAutoIncrement() {
-			continue
-		}
-		values.Add(c.Name, values.Len()/2+1)
-	}
-	if values.Empty() {
-		return nil, t.errNoColumns()
+	auto := t.AutoIncrement()
+	if !query.Supports(h.Starter, query.FeatureAutoIncrement) {
+		auto = nil
 	}
-	returning := false
-	q := query.Q(query.Insert(t.Name), values)
-	if c := t.AutoIncrement(); c != nil {
-		if name := h.Starter.Quote(c.Name); len(name) == 0 {
-			return nil, c.errUnsupported()
-		} else if s := h.Starter.Returning('c', name); len(s) > 0 {
-			returning = true
-			q.Append(query.Literal(s))
-		}
-	}
-	s, _, err := h.Prepare(q)
+	a := t.returning('c', auto)
+	output, returning, err := h.returning('c', a)
 	if err != nil {
 		return nil, err
 	}
+	if len(output) == 0 && len(returning) == 0 {
+		a = nil
+	}
+	m := make(map[string]*stmt)
 	defer func() {
-		log.ErrWarning(s.Close())
+		for _, s := range m {
+			h.logWarning(s.Close())
+		}
 	}()
-	return h.create(returning, s, t, v)
+	prepare := func(b Columns) (*stmt, error) {
+		k := fmt.Sprint(b.Strings())
+		if s, ok := m[k]; ok {
+			return s, nil
+		}
+		values := query.X.Values()
+		if len(output) > 0 {
+			values = query.Q3S2("(", ", ", ") "+output+" VALUES (", ", ", ")")
+		}
+		for _, c := range b {
+			values.Add(c.Name, values.Len()/2+1)
+		}
+		if values.Empty() {
+			return nil, t.errNoColumns()
+		}
+		q := query.Q(query.Insert(t.Name), values)
+		if len(returning) > 0 {
+			q.Append(query.Literal(returning))
+		}
+		s, err := h.prepareStmt(t.Name, q)
+		if err == nil {
+			m[k] = s
+		}
+		return s, err
+	}
+	return h.create(a, prepare, t, auto, v)
 }
 
-func (h Huge) create(returning bool, s *sql.Stmt, t *Table, v reflect.Value) (_ interface{}, err error) {
-	now := time.Now()
+func (h Huge) create(returning Columns, prepare func(Columns) (*stmt, error), t *Table, auto *Column, v reflect.Value) (_ interface{}, err error) {
+	now := h.now()
 	switch v.Kind() {
 	case reflect.Map:
 		m := reflect.MakeMap(reflect.MapOf(v.Type().Key(), typeEmpty))
 		for _, i := range v.MapKeys() {
-			err = h.create1(returning, s, t, v.MapIndex(i), now)
+			err = h.create1(returning, prepare, t, auto, v.MapIndex(i), now)
 			if err != nil {
 				break
 			}
@@ -105,30 +119,56 @@ func (h Huge) create(returning bool, s *sql.Stmt, t *Table, v reflect.Value) (_
 	case reflect.Slice:
 		i := 0
 		for n := v.Len(); i < n; i++ {
-			err = h.create1(returning, s, t, v.Index(i), now)
+			err = h.create1(returning, prepare, t, auto, v.Index(i), now)
 			if err != nil {
 				break
 			}
 		}
 		return i, err
 	}
-	err = h.create1(returning, s, t, v, now)
+	err = h.create1(returning, prepare, t, auto, v, now)
 	return err == nil, err
 }
 
-func (h Huge) create1(returning bool, s *sql.Stmt, t *Table, v reflect.Value, now time.Time) (err error) {
+// create1 reads back the returning columns if any, leaves auto nil if the auto increment column is given by the row,
+// omits the default columns of zero value read back.
+func (h Huge) create1(returning Columns, prepare func(Columns) (*stmt, error), t *Table, auto *Column, v reflect.Value, now time.Time) (err error) {
+	defer func() {
+		if err == nil {
+			t.takeSnapshot(v, nil)
+		}
+	}()
 	if v.Kind() == reflect.Ptr {
 		if v.IsNil() {
 			return t.errNil()
 		}
 		v = v.Elem()
 	}
-	a := make([]interface{}, 0, len(t.a))
+	b := make(Columns, 0, len(t.a))
 	for _, c := range t.a {
-		if c.isMany() || c.isAutoIncrement() {
+		if c.isMany() || c == auto || c.isGenerated() {
 			continue
+		} else if c.isDefault() && returning.has(c) {
+			if x, ok := c.field(v); !ok || isZero(x) {
+				continue
+			}
 		}
+		b = append(b, c)
+	}
+	s, err := prepare(b)
+	if err != nil {
+		return
+	}
+	a := make([]interface{}, 0, len(b))
+	for _, c := range b {
 		var i interface{}
+		if c.isAutoIncrement() {
+			if j, ok := c.getInteger(v); !ok {
+				return c.errGet()
+			} else if j == 0 {
+				return c.errZero()
+			}
+		}
 		if c.isVersion() {
 			if i = c.convertInteger(1); i == nil {
 				return c.errSet()
@@ -160,33 +200,32 @@ func (h Huge) create1(returning bool, s *sql.Stmt, t *Table, v reflect.Value, no
 		}
 		return nil
 	}
-	c := t.AutoIncrement()
-	if returning {
-		i, f, ok := c.scan(v)
-		if !ok {
-			return c.errSet()
+	if len(returning) > 0 {
+		d, f, err := scanColumns(returning, v, h.Location)
+		if err != nil {
+			return err
 		}
-		err = s.QueryRow(a...).Scan(i)
-		if err == nil && f != nil {
+		err = s.queryRow(a, d...)
+		if err == nil {
 			err = f()
 		}
 		if err == nil {
 			err = set()
 		}
-		return
+		return err
 	}
 	r, err := s.Exec(a...)
 	if err != nil {
 		return
 	}
-	if c != nil {
+	if c := auto; c != nil {
 		if i, err := r.LastInsertId(); err != nil {
 			return err
 		} else if !c.setInteger(v, i) {
 			return c.errSet()
 		}
 	}
-	n, err := r.RowsAffected()
+	n, err := h.rowsAffected(r, 1)
 	if err != nil {
 		return
 	}
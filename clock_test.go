@@ -0,0 +1,59 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"testing"
+	"time"
+)
+
+type Note struct {
+	Id      int64
+	Title   string
+	Created time.Time `huge:",auto_now_add"`
+	Updated time.Time `huge:",auto_now"`
+}
+
+func TestFakeClock(t *testing.T) {
+	f := NewFake()
+	h := f.Open("mysql")
+	h.Location, h.TimePrec = time.UTC, 6
+	c := NewFakeClock(time.Date(2024, 1, 2, 23, 4, 5, 123456789, time.UTC))
+	h.Clock = c
+	a := time.Date(2024, 1, 2, 23, 4, 5, 123456000, time.UTC)
+	b := time.Date(2024, 1, 3, 0, 4, 5, 123456000, time.UTC)
+	f.ExpectNormalized("INSERT INTO note (title, created, updated) VALUES (?, ?, ?)").
+		Args("a", a, a).Result(1, 1)
+	f.ExpectNormalized("UPDATE note SET title = ?, updated = ? WHERE id = ?").
+		Args("b", b, 1).Result(0, 1)
+	if h.Now() != a {
+		t.Fatal("now:", h.Now())
+	} else if d := h.Today(); d != NewDate(2024, 1, 2) {
+		t.Fatal("today:", d)
+	}
+	n := &Note{Title: "a"}
+	if _, err := h.Create(n); err != nil {
+		t.Fatal(err)
+	} else if n.Id != 1 || n.Created != a || n.Updated != a {
+		t.Fatalf("create: %+v", n)
+	}
+	c.Add(time.Hour)
+	n.Title = "b"
+	if ok, err := h.Update(n, "Title"); err != nil || ok != true {
+		t.Fatal(ok, err)
+	} else if n.Created != a || n.Updated != b {
+		t.Fatalf("update: %+v", n)
+	}
+	if d := h.Today(); d != NewDate(2024, 1, 3) {
+		t.Fatal("today:", d)
+	}
+	h.Location = time.FixedZone("UTC-2", -2*3600)
+	if d := h.Today(); d != NewDate(2024, 1, 2) {
+		t.Fatal("today:", d)
+	}
+	if err := f.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
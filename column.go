@@ -5,24 +5,31 @@
 package huge
 
 import (
-	"bytes"
-	"encoding/gob"
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cxr29/huge/query"
 )
 
 const (
-	oAutoIncrement = 1 << iota
+	oArray = 1 << iota
+	oAutoIncrement
 	oAutoNow
 	oAutoNowAdd
 	oCollapse
+	oCompress
+	oDate
+	oDecimal
+	oDefault
+	oDeterministic
+	oEncoding
+	oEncrypt
 	oForeignKey
+	oFulltext
+	oGenerated
 	oGob
 	oInline
 	oInlineStatic
@@ -35,6 +42,7 @@ const (
 	oPointer
 	oPrimaryKey
 	oScanner
+	oTimeOfDay
 	oUnique
 	oValuer
 	oVersion
@@ -46,7 +54,7 @@ const (
 )
 
 var options = map[string]struct {
-	u uint
+	u uint64
 	b byte
 	f func(reflect.Type) bool
 }{
@@ -54,7 +62,14 @@ var options = map[string]struct {
 	"auto_now":       {oAutoNow, 'a', isTimes},
 	"auto_now_add":   {oAutoNowAdd, 'a', isTimes},
 	"collapse":       {oCollapse, 'c', nil},
+	"date":           {oDate, 'y', isDateOrTime},
+	"decimal":        {oDecimal, 'e', isDecimals},
+	"default":        {oDefault, 'd', nil},
+	"deterministic":  {oDeterministic, 't', nil},
+	"encrypt":        {oEncrypt, 'x', nil},
 	"foreign_key":    {oForeignKey, 'r', isStruct},
+	"fulltext":       {oFulltext, 'f', isString},
+	"generated":      {oGenerated, 'd', nil},
 	"gob":            {oGob, 'e', nil},
 	"inline":         {oInline, 'i', isStruct},
 	"inline_static":  {oInlineStatic, 'i', isStruct},
@@ -64,12 +79,13 @@ var options = map[string]struct {
 	"one_to_many":    {oOneToMany, 'r', isStructs},
 	"one_to_one":     {oOneToOne, 'r', isStruct},
 	"primary_key":    {oPrimaryKey, 'p', nil},
+	"time_of_day":    {oTimeOfDay, 'y', isTime},
 	"unique":         {oUnique, 'u', nil},
 	"version":        {oVersion, 'a', isIntegers},
 	"xml":            {oXML, 'e', nil},
 }
 
-func option(u uint) string {
+func option(u uint64) string {
 	for k, v := range options {
 		if v.u == u {
 			return k
@@ -78,7 +94,7 @@ func option(u uint) string {
 	panic(false)
 }
 
-func parseOptions(t reflect.Type, s string) (e, n string, u uint, size int) {
+func parseOptions(t reflect.Type, s string) (e, n string, u uint64, size, scale int, c codec) {
 	if s == "-" {
 		panic(false)
 	}
@@ -93,20 +109,51 @@ func parseOptions(t reflect.Type, s string) (e, n string, u uint, size int) {
 		u |= oPointer
 	}
 	if len(s) == 0 {
+		if u&(oValuer|oPointer) == 0 && query.IsArray(t) {
+			u |= oArray
+		}
 		return
 	}
 	m := make(map[byte]string, 4)
 	for k, v := range strings.Split(s, ",") {
 		if k == 0 {
 			n = v
-		} else if o, ok := options[v]; !ok {
+			continue
+		}
+		if strings.HasPrefix(v, "decimal:") {
+			a := strings.Split(v, ":")
+			var err error
+			if len(a) == 3 {
+				if size, err = strconv.Atoi(a[1]); err == nil {
+					scale, err = strconv.Atoi(a[2])
+				}
+			}
+			if len(a) != 3 || err != nil || size < 1 || scale < 0 || scale > size {
+				e = fmt.Sprintf("invalid option: %s", v)
+				return
+			}
+			v = "decimal"
+		}
+		if o, ok := options[v]; !ok {
 			if i, err := strconv.Atoi(v); err == nil {
 				size = i
 				continue
+			}
+			x, z := codecOf(v)
+			var b byte
+			if x != nil {
+				b, u, c.e = 'e', u|oEncoding, x
+			} else if z != nil {
+				b, u, c.z = 'z', u|oCompress, z
 			} else {
 				e = fmt.Sprintf("unsupported option: %s", v)
 				return
 			}
+			if s, ok := m[b]; ok {
+				e = fmt.Sprintf("option %s conflict with option %s", s, v)
+				return
+			}
+			m[b] = v
 		} else if u&o.u == o.u {
 			e = fmt.Sprintf("duplicate option %s", v)
 			return
@@ -121,6 +168,26 @@ func parseOptions(t reflect.Type, s string) (e, n string, u uint, size int) {
 			m[o.b] = v
 		}
 	}
+	if u&(oValuer|oPointer) == 0 && m['e'] == "" && query.IsArray(t) {
+		u |= oArray
+	}
+	if s, ok := m['z']; ok && (m['e'] == "" || u&oDecimal == oDecimal) {
+		e = fmt.Sprintf("option %s needs an encoding", s)
+		return
+	}
+	if s, ok := m['t']; ok && m['x'] == "" {
+		e = fmt.Sprintf("option %s needs option encrypt", s)
+		return
+	} else if s, ok := m['x']; ok {
+		if _, ok := m['e']; !ok && (!isStringOrBytes(t) || u&oPointer != 0 || u&oDecimal != 0) {
+			e = fmt.Sprintf("type mismatch option %s", s)
+			return
+		}
+		u &^= oArray
+	}
+	if u&oDecimal == oDecimal && size == 0 {
+		size, scale = 18, 4
+	}
 	if s, ok := m['i']; ok && len(m) > 1 {
 		e = fmt.Sprintf("option %s conflict with others", s)
 		return
@@ -139,13 +206,16 @@ func parseOptions(t reflect.Type, s string) (e, n string, u uint, size int) {
 
 type Field struct {
 	t           reflect.Type
-	o           uint
+	o           uint64
 	i, j, size  int
+	scale       int
+	c           codec
+	x           *Mapping
 	belong, own *Struct
 	name, alias string
 }
 
-func (f *Field) Is(o uint) bool {
+func (f *Field) Is(o uint64) bool {
 	return f.o&o == o
 }
 
@@ -154,7 +224,7 @@ func (f *Field) IsAuto() bool {
 }
 
 func (f *Field) IsEncoding() bool {
-	return f.Is(oGob) || f.Is(oJSON) || f.Is(oXML)
+	return f.Is(oGob) || f.Is(oJSON) || f.Is(oXML) || f.Is(oEncoding)
 }
 
 func (f *Field) IsInline() bool {
@@ -189,7 +259,7 @@ type Column struct {
 	t, r *Table
 	i    int
 	a    []*Field
-	o    uint
+	o    uint64
 	Name string
 	query.Operand
 }
@@ -297,7 +367,7 @@ func (c *Column) cache() {
 		c.o |= oOne
 	}
 }
-func (c *Column) is(o uint) bool {
+func (c *Column) is(o uint64) bool {
 	return c.o&o == o
 }
 func (c *Column) isAutoIncrement() bool {
@@ -312,6 +382,12 @@ func (c *Column) isAutoNowAdd() bool {
 func (c *Column) isCollapse() bool {
 	return c.is(oCollapse)
 }
+func (c *Column) isDefault() bool {
+	return c.last().Is(oDefault)
+}
+func (c *Column) isGenerated() bool {
+	return c.last().Is(oGenerated)
+}
 func (c *Column) isPrimaryKey() bool {
 	return c.is(oPrimaryKey)
 }
@@ -321,6 +397,9 @@ func (c *Column) isVersion() bool {
 func (c *Column) canNil() bool {
 	return c.is(oNil)
 }
+func (c *Column) isNullable() bool {
+	return !c.isPrimaryKey() && (c.canNil() || c.isCollapse())
+}
 func (c *Column) isMany() bool {
 	return c.is(oMany)
 }
@@ -352,12 +431,38 @@ func (c *Column) field(v reflect.Value) (reflect.Value, bool) {
 	}
 	return v, true
 }
-func (c *Column) scan(v reflect.Value) (interface{}, func() error, bool) {
+
+// scan to v, times converted to loc if not nil.
+func (c *Column) scan(v reflect.Value, loc *time.Location) (interface{}, func() error, bool) {
 	if v, ok := c.field(v); ok {
-		if f := c.last(); !f.Is(oScanner) {
-			if f.IsEncoding() {
-				if ((c.isCollapse() || f.Is(oGob)) && !v.CanSet()) ||
-					((f.Is(oJSON) || f.Is(oXML)) && !v.CanAddr()) {
+		if f := c.last(); f.isTime(loc) {
+			if !v.CanSet() {
+				return nil, nil, false
+			}
+			p, g := f.scanTime(v, loc)
+			return p, g, true
+		} else if f.Is(oEncrypt) {
+			if !v.CanSet() {
+				return nil, nil, false
+			}
+			var b []byte
+			return &b, func() error {
+				return c.decrypt(b, v)
+			}, true
+		} else if f.x != nil && f.x.Scan != nil && !f.IsEncoding() {
+			if v.CanSet() {
+				p, g := f.scanner(v)
+				return p, g, true
+			}
+			return nil, nil, false
+		} else if !f.Is(oScanner) {
+			if f.Is(oArray) {
+				if v.CanAddr() {
+					return &query.Array{V: v.Addr().Interface()}, nil, true
+				}
+				return nil, nil, false
+			} else if f.IsEncoding() {
+				if ((c.isCollapse() || f.Is(oGob)) && !v.CanSet()) || (!f.Is(oGob) && !v.CanAddr()) {
 					return nil, nil, false
 				}
 				var b []byte
@@ -365,14 +470,8 @@ func (c *Column) scan(v reflect.Value) (interface{}, func() error, bool) {
 					if c.isCollapse() && len(b) == 0 {
 						v.Set(reflect.Zero(f.t))
 						return nil
-					} else if f.Is(oGob) {
-						return gob.NewDecoder(bytes.NewReader(b)).DecodeValue(v)
-					} else if f.Is(oJSON) {
-						return json.Unmarshal(b, v.Addr().Interface())
-					} else if f.Is(oXML) {
-						return xml.Unmarshal(b, v.Addr().Interface())
 					}
-					panic(false)
+					return f.decode(b, v)
 				}, true
 			} else if c.isCollapse() {
 				if v.CanSet() {
@@ -395,29 +494,45 @@ func (c *Column) scan(v reflect.Value) (interface{}, func() error, bool) {
 	}
 	return nil, nil, false
 }
-func (c *Column) scanNew() (interface{}, scanNewFunc) {
+
+// scanNew of c, times converted to loc if not nil.
+func (c *Column) scanNew(loc *time.Location) (interface{}, scanNewFunc) {
 	f := c.last()
-	if !f.Is(oScanner) {
-		if f.IsEncoding() {
+	if f.isTime(loc) {
+		v := reflect.New(f.t).Elem()
+		p, g := f.scanTime(v, loc)
+		return p, func() (reflect.Value, error) {
+			return v, g()
+		}
+	} else if f.Is(oEncrypt) {
+		var b []byte
+		return &b, func() (reflect.Value, error) {
+			v := reflect.New(f.t).Elem()
+			return v, c.decrypt(b, v)
+		}
+	} else if f.x != nil && f.x.Scan != nil && !f.IsEncoding() {
+		v := reflect.New(f.t).Elem()
+		p, g := f.scanner(v)
+		return p, func() (reflect.Value, error) {
+			return v, g()
+		}
+	} else if !f.Is(oScanner) {
+		if f.Is(oArray) {
+			p := reflect.New(f.t)
+			return &query.Array{V: p.Interface()}, func() (reflect.Value, error) {
+				return p.Elem(), nil
+			}
+		} else if f.IsEncoding() {
 			var b []byte
 			return &b, func() (_ reflect.Value, err error) {
 				if c.isCollapse() && len(b) == 0 {
 					return reflect.Zero(f.t), nil
 				}
-				v := reflect.New(f.t)
-				if f.Is(oGob) {
-					err = gob.NewDecoder(bytes.NewReader(b)).DecodeValue(v)
-				} else if f.Is(oJSON) {
-					err = json.Unmarshal(b, v.Interface())
-				} else if f.Is(oXML) {
-					err = xml.Unmarshal(b, v.Interface())
-				} else {
-					panic(false)
-				}
-				if err != nil {
+				v := reflect.New(f.t).Elem()
+				if err = f.decode(b, v); err != nil {
 					return
 				}
-				return v.Elem(), nil
+				return v, nil
 			}
 		} else if c.isCollapse() {
 			p := reflect.New(reflect.PtrTo(f.t))
@@ -443,27 +558,56 @@ func (c *Column) getBy(collapse, encoding bool, v reflect.Value) (interface{}, e
 	return nil, c.errGet()
 }
 func (c *Column) convert(collapse, encoding bool, v reflect.Value) (interface{}, error) {
-	if f := c.last(); !f.Is(oValuer) {
+	i, err := c.plain(collapse, encoding, v)
+	if err == nil && encoding && c.last().Is(oEncrypt) {
+		return c.encrypt(i)
+	}
+	return i, err
+}
+func (c *Column) plain(collapse, encoding bool, v reflect.Value) (interface{}, error) {
+	if f := c.last(); encoding && f.x != nil && f.x.Value != nil && !f.IsEncoding() {
+		if collapse && c.isCollapse() && isZero(v) {
+			return nil, nil
+		} else if f.Is(oPointer) {
+			if v.IsNil() {
+				return nil, nil
+			}
+			v = v.Elem()
+		}
+		if v.CanInterface() {
+			return f.x.Value(v.Interface())
+		}
+		return nil, c.errGet()
+	} else if encoding && (f.Is(oDate) || f.Is(oTimeOfDay)) {
 		if collapse && c.isCollapse() && isZero(v) {
 			return nil, nil
+		} else if f.Is(oPointer) {
+			if v.IsNil() {
+				return nil, nil
+			}
+			v = v.Elem()
+		}
+		if !v.CanInterface() {
+			return nil, c.errGet()
+		} else if d, ok := v.Interface().(Date); ok {
+			if !d.IsValid() {
+				return nil, nil
+			}
+			return d.String(), nil
+		}
+		return f.formatTime(v.Interface().(time.Time)), nil
+	} else if !f.Is(oValuer) {
+		if collapse && c.isCollapse() && isZero(v) {
+			return nil, nil
+		} else if encoding && f.Is(oDecimal) && (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) {
+			return strconv.FormatFloat(v.Float(), 'f', f.scale, v.Type().Bits()), nil
+		} else if encoding && f.Is(oArray) && v.CanInterface() {
+			return query.Array{V: v.Interface()}, nil // PostgreSQL only, checked by bind
 		} else if encoding && f.IsEncoding() {
-			var b []byte
-			var err error
-			if f.Is(oGob) {
-				var buf bytes.Buffer
-				err = gob.NewEncoder(&buf).EncodeValue(v)
-				b = buf.Bytes()
-			} else if v.CanInterface() {
-				if f.Is(oJSON) {
-					b, err = json.Marshal(v.Interface())
-				} else if f.Is(oXML) {
-					b, err = xml.Marshal(v.Interface())
-				} else {
-					panic(false)
-				}
-			} else {
+			if !f.Is(oGob) && !v.CanInterface() {
 				return nil, c.errGet()
 			}
+			b, err := f.encode(v)
 			if err != nil {
 				return nil, err
 			} else if collapse && c.isCollapse() && len(b) == 0 {
@@ -502,13 +646,30 @@ func (c *Column) errUnsupported() error {
 }
 
 func (f *Field) typeName() string {
+	if f.Is(oEncrypt) {
+		return "bytes"
+	} else if f.Is(oDate) {
+		return "date"
+	} else if f.Is(oTimeOfDay) {
+		return "time_of_day"
+	} else if f.Is(oDecimal) {
+		return fmt.Sprintf("decimal:%d:%d", f.size, f.scale)
+	} else if f.x != nil && !f.IsEncoding() {
+		return f.x.Name
+	}
 	if !f.Is(oValuer) {
-		if f.Is(oGob) {
+		if f.Is(oCompress) || (f.Is(oEncoding) && f.c.e.Binary) {
+			return "bytes"
+		} else if f.Is(oEncoding) {
+			return "text"
+		} else if f.Is(oGob) {
 			return "gob"
 		} else if f.Is(oJSON) {
 			return "json"
 		} else if f.Is(oXML) {
 			return "xml"
+		} else if f.Is(oArray) {
+			return "[]" + typeName(f.t.Elem())
 		}
 	}
 	return typeName(f.t)
@@ -522,6 +683,8 @@ func typeName(t reflect.Type) string {
 	switch t {
 	case typeTime:
 		return "time"
+	case typeDecimal:
+		return "decimal"
 	case typeInterface:
 		return "interface"
 	case typeNullBool:
@@ -5,12 +5,10 @@
 package huge
 
 import (
-	"database/sql"
 	"fmt"
 	"reflect"
 
 	"github.com/cxr29/huge/query"
-	"github.com/cxr29/log"
 )
 
 // Delete T returns bool, []T returns map[int]struct{}, map[]T returns map[]struct{}.
@@ -20,24 +18,33 @@ func (h Huge) Delete(i interface{}) (interface{}, error) {
 	if t.PrimaryKey() == nil {
 		panic(t.errNoPrimaryKey())
 	}
-	s := make([]*sql.Stmt, 2)
+	r := t.returning('d', nil)
+	output, returning, err := h.returning('d', r)
+	if err != nil {
+		return nil, err
+	} else if len(output) > 0 {
+		returning = output
+	} else if len(returning) == 0 {
+		r = nil
+	}
+	s := make([]*stmt, 2)
 	defer func() {
 		for _, i := range s {
 			if i != nil {
-				log.ErrWarning(i.Close())
+				h.logWarning(i.Close())
 			}
 		}
 	}()
-	return h.remove(s, t, v)
+	return h.remove(returning, r, s, t, v)
 }
 
-func (h Huge) remove(s []*sql.Stmt, t *Table, v reflect.Value) (_ interface{}, err error) {
+func (h Huge) remove(returning string, r Columns, s []*stmt, t *Table, v reflect.Value) (_ interface{}, err error) {
 	var b bool
 	switch v.Kind() {
 	case reflect.Map:
 		m := reflect.MakeMap(reflect.MapOf(v.Type().Key(), typeEmpty))
 		for _, i := range v.MapKeys() {
-			b, err = h.remove1(s, t, v.MapIndex(i))
+			b, err = h.remove1(returning, r, s, t, v.MapIndex(i))
 			if err != nil {
 				break
 			}
@@ -50,7 +57,7 @@ func (h Huge) remove(s []*sql.Stmt, t *Table, v reflect.Value) (_ interface{}, e
 		n := v.Len()
 		m := make(map[int]struct{}, n)
 		for i := 0; i < n; i++ {
-			b, err = h.remove1(s, t, v.Index(i))
+			b, err = h.remove1(returning, r, s, t, v.Index(i))
 			if err != nil {
 				break
 			}
@@ -60,10 +67,13 @@ func (h Huge) remove(s []*sql.Stmt, t *Table, v reflect.Value) (_ interface{}, e
 		}
 		return m, err
 	}
-	return h.remove1(s, t, v)
+	return h.remove1(returning, r, s, t, v)
 }
 
-func (h Huge) remove1(s []*sql.Stmt, t *Table, v reflect.Value) (_ bool, err error) {
+func (h Huge) remove1(returning string, r Columns, s []*stmt, t *Table, v reflect.Value) (_ bool, err error) {
+	if err = h.checkVersion(t); err != nil {
+		return
+	}
 	if v.Kind() == reflect.Ptr {
 		if v.IsNil() {
 			return false, t.errNil()
@@ -81,18 +91,36 @@ func (h Huge) remove1(s []*sql.Stmt, t *Table, v reflect.Value) (_ bool, err err
 		if j == 1 {
 			where.And(t.Version().Eq(2))
 		}
-		s[j], _, err = h.Prepare(query.Q(
-			query.Delete(t.Name), where,
-		))
+		q := query.Q(h.deleteFrom(t.Name))
+		if len(returning) == 0 {
+			q.Append(where)
+		} else if _, ok := h.Starter.(query.Outputer); ok {
+			q.Append(query.Literal(returning), where)
+		} else {
+			q.Append(where, query.Literal(returning))
+		}
+		s[j], err = h.prepareStmt(t.Name, q)
 		if err != nil {
 			return
 		}
 	}
-	r, err := s[j].Exec(p...)
+	if len(r) > 0 && v.CanAddr() {
+		d, f, err := scanColumns(r, v, h.Location)
+		if err != nil {
+			return false, err
+		}
+		if err = s[j].queryRow(p, d...); err == ErrNoRows {
+			return false, nil
+		} else if err == nil {
+			err = f()
+		}
+		return err == nil, err
+	}
+	x, err := s[j].Exec(p...)
 	if err != nil {
 		return
 	}
-	n, err := r.RowsAffected()
+	n, err := h.rowsAffected(x, 1)
 	if err != nil {
 		return
 	}
@@ -104,7 +132,8 @@ func (h Huge) remove1(s []*sql.Stmt, t *Table, v reflect.Value) (_ bool, err err
 	panic(fmt.Errorf("huge: RowsAffected expected 0 or 1 but was %d", n))
 }
 
-// DeleteBy PK, []PK, map[PK] returns the number of rows affected by delete.
+// DeleteBy PK, []PK, map[PK] returns the number of rows affected by delete,
+// or submitted if the Starter can not tell, like the mutations of ClickHouse.
 func (h Huge) DeleteBy(primaryKeys, row interface{}) (int64, error) {
 	_, i, err := h.rud('d', primaryKeys, row, nil)
 	return i, err
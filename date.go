@@ -0,0 +1,269 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/cxr29/huge/query"
+)
+
+// Date of integer yyyymmdd, negative for BC, the zero value is invalid,
+// an INTEGER column or with option date a native DATE column,
+// bound as integer in conditions, but as String by the comparisons of native columns,
+// and NULL there if invalid.
+type Date int
+
+var typeDate = reflect.TypeOf(Date(0))
+
+// NewDate of y, m and d, zero if invalid.
+func NewDate(y, m, d int) Date {
+	x := y < 0
+	if x {
+		y = -y
+	}
+	return Date(newDate(y, m, d, x))
+}
+
+// DateOf the date of t in its location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return NewDate(y, int(m), d)
+}
+
+// Today of time.Now in time.Local, Huge.Today for the Clock and Location.
+func Today() Date {
+	return DateOf(time.Now())
+}
+
+// Today of the Clock in Location if not nil.
+func (h Huge) Today() Date {
+	return DateOf(h.now())
+}
+
+// date of i for the comparisons of c, Date as String if c is a native DATE column,
+// nil if invalid.
+func (c *Column) date(i interface{}) interface{} {
+	if c.last().Is(oDate) {
+		switch d := i.(type) {
+		case *Date:
+			if d == nil {
+				return nil
+			}
+			return c.date(*d)
+		case Date:
+			if !d.IsValid() {
+				return nil
+			}
+			return d.String()
+		}
+	}
+	return i
+}
+
+// Lt and the other comparisons of i convert Date like Eq, errors on encrypted columns.
+func (c *Column) Lt(i interface{}) query.Condition {
+	if c.last().Is(oEncrypt) {
+		return c.unordered()
+	}
+	return c.Operand.Lt(c.date(i))
+}
+
+func (c *Column) Le(i interface{}) query.Condition {
+	if c.last().Is(oEncrypt) {
+		return c.unordered()
+	}
+	return c.Operand.Le(c.date(i))
+}
+
+func (c *Column) Gt(i interface{}) query.Condition {
+	if c.last().Is(oEncrypt) {
+		return c.unordered()
+	}
+	return c.Operand.Gt(c.date(i))
+}
+
+func (c *Column) Ge(i interface{}) query.Condition {
+	if c.last().Is(oEncrypt) {
+		return c.unordered()
+	}
+	return c.Operand.Ge(c.date(i))
+}
+
+func (c *Column) Between(i, j interface{}) query.Condition {
+	if c.last().Is(oEncrypt) {
+		return c.unordered()
+	}
+	return c.Operand.Between(c.date(i), c.date(j))
+}
+
+func (d Date) IsValid() bool {
+	return IsDate(int(d))
+}
+
+func (d Date) Date() (y, m, day int) {
+	y, m, day, x := splitDate(int(d))
+	if x {
+		y = -y
+	}
+	return
+}
+
+// Time at midnight in loc.
+func (d Date) Time(loc *time.Location) time.Time {
+	y, m, day := d.Date()
+	return time.Date(y, time.Month(m), day, 0, 0, 0, 0, loc)
+}
+
+func (d Date) Weekday() time.Weekday {
+	return d.Time(time.UTC).Weekday()
+}
+
+func (d Date) days() int64 {
+	return d.Time(time.UTC).Unix() / 86400
+}
+
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.Time(time.UTC).AddDate(0, 0, n))
+}
+
+// AddMonths clamped to the last day of the month, like Jan 31 plus 1 is Feb 28 or 29.
+func (d Date) AddMonths(n int) Date {
+	y, m, day := d.Date()
+	m += n
+	y += (m - 1) / 12
+	if m = (m-1)%12 + 1; m < 1 {
+		m += 12
+		y--
+	}
+	if i := maxMonthDay(m, isLeap(y)); day > i {
+		day = i
+	}
+	return NewDate(y, m, day)
+}
+
+func (d Date) AddYears(n int) Date {
+	return d.AddMonths(n * 12)
+}
+
+// Sub days of d minus e.
+func (d Date) Sub(e Date) int {
+	return int(d.days() - e.days())
+}
+
+func (d Date) Compare(e Date) int {
+	switch i, j := d.days(), e.days(); {
+	case i < j:
+		return -1
+	case i > j:
+		return 1
+	}
+	return 0
+}
+
+func (d Date) Before(e Date) bool {
+	return d.Compare(e) < 0
+}
+
+func (d Date) After(e Date) bool {
+	return d.Compare(e) > 0
+}
+
+func (d Date) String() string {
+	return FormatDate(int(d))
+}
+
+func (d Date) Value() (driver.Value, error) {
+	return int64(d), nil
+}
+
+func (d *Date) Scan(src interface{}) error {
+	switch x := src.(type) {
+	case nil:
+		*d = 0
+	case int64:
+		*d = Date(x)
+	case time.Time:
+		*d = DateOf(x)
+	case []byte:
+		return d.UnmarshalText(x)
+	case string:
+		return d.UnmarshalText([]byte(x))
+	default:
+		return fmt.Errorf("huge: date scan unsupported: %T", src)
+	}
+	return nil
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText of yyyy-mm-dd, with time after if any, or yyyymmdd.
+func (d *Date) UnmarshalText(b []byte) error {
+	s := string(b)
+	if len(s) > 10 && (s[10] == ' ' || s[10] == 'T') {
+		s = s[:10]
+	}
+	if n := ParseDate(s); n != 0 {
+		*d = Date(n)
+	} else if n, err := strconv.Atoi(s); err == nil && IsDate(n) {
+		*d = Date(n)
+	} else {
+		return fmt.Errorf("huge: invalid date: %q", b)
+	}
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d == 0 {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*d = 0
+		return nil
+	}
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return fmt.Errorf("huge: invalid date: %s", b)
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// DateRange from and to inclusive.
+type DateRange struct {
+	From, To Date
+}
+
+func (r DateRange) Contains(d Date) bool {
+	return !d.Before(r.From) && !d.After(r.To)
+}
+
+// Days of r, 0 if To is before From.
+func (r DateRange) Days() int {
+	if n := r.To.Sub(r.From) + 1; n > 0 {
+		return n
+	}
+	return 0
+}
+
+// Each date of r until f returns false.
+func (r DateRange) Each(f func(Date) bool) {
+	for d, n := r.From, r.Days(); n > 0; n-- {
+		if !f(d) {
+			return
+		}
+		d = d.AddDays(1)
+	}
+}
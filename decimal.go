@@ -0,0 +1,225 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number of an unscaled integer times ten to the power of -scale,
+// bound as text and scanned from text, integer or float, the zero value is 0.
+type Decimal struct {
+	i     *big.Int
+	scale int32
+}
+
+var typeDecimal = reflect.TypeOf(Decimal{})
+
+var bigTen = big.NewInt(10)
+
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	return Decimal{big.NewInt(unscaled), scale}
+}
+
+// ParseDecimal like -12.345 or 1.2e3.
+func ParseDecimal(s string) (Decimal, error) {
+	t := strings.TrimSpace(s)
+	var exp int64
+	if i := strings.IndexAny(t, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(t[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("huge: invalid decimal: %s", s)
+		}
+		exp, t = e, t[:i]
+	}
+	neg := false
+	if len(t) > 0 && (t[0] == '-' || t[0] == '+') {
+		neg, t = t[0] == '-', t[1:]
+	}
+	var scale int64
+	if i := strings.IndexByte(t, '.'); i >= 0 {
+		scale = int64(len(t) - i - 1)
+		t = t[:i] + t[i+1:]
+	}
+	if len(t) == 0 {
+		return Decimal{}, fmt.Errorf("huge: invalid decimal: %s", s)
+	}
+	for i := 0; i < len(t); i++ {
+		if t[i] < '0' || t[i] > '9' {
+			return Decimal{}, fmt.Errorf("huge: invalid decimal: %s", s)
+		}
+	}
+	i, _ := new(big.Int).SetString(t, 10)
+	if neg {
+		i.Neg(i)
+	}
+	scale -= exp
+	if scale < 0 {
+		i.Mul(i, new(big.Int).Exp(bigTen, big.NewInt(-scale), nil))
+		scale = 0
+	}
+	return Decimal{i, int32(scale)}, nil
+}
+
+func (d Decimal) int() *big.Int {
+	if d.i == nil {
+		return new(big.Int)
+	}
+	return d.i
+}
+
+// Unscaled integer and scale.
+func (d Decimal) Unscaled() (*big.Int, int32) {
+	return new(big.Int).Set(d.int()), d.scale
+}
+
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// rescale to a larger scale.
+func (d Decimal) rescale(scale int32) *big.Int {
+	i := d.int()
+	if scale > d.scale {
+		i = new(big.Int).Mul(i, new(big.Int).Exp(bigTen, big.NewInt(int64(scale-d.scale)), nil))
+	}
+	return i
+}
+
+func maxScale(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (d Decimal) Cmp(e Decimal) int {
+	s := maxScale(d.scale, e.scale)
+	return d.rescale(s).Cmp(e.rescale(s))
+}
+
+func (d Decimal) Add(e Decimal) Decimal {
+	s := maxScale(d.scale, e.scale)
+	return Decimal{new(big.Int).Add(d.rescale(s), e.rescale(s)), s}
+}
+
+func (d Decimal) Sub(e Decimal) Decimal {
+	s := maxScale(d.scale, e.scale)
+	return Decimal{new(big.Int).Sub(d.rescale(s), e.rescale(s)), s}
+}
+
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{new(big.Int).Mul(d.int(), e.int()), d.scale + e.scale}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{new(big.Int).Neg(d.int()), d.scale}
+}
+
+// Round to scale, half away from zero.
+func (d Decimal) Round(scale int32) Decimal {
+	if scale >= d.scale {
+		return Decimal{d.rescale(scale), scale}
+	}
+	n := new(big.Int).Exp(bigTen, big.NewInt(int64(d.scale-scale)), nil)
+	q, r := new(big.Int).QuoRem(d.int(), n, new(big.Int))
+	if r.Abs(r).Lsh(r, 1).Cmp(n) >= 0 {
+		if d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Decimal{q, scale}
+}
+
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+func (d Decimal) String() string {
+	s := d.int().String()
+	if d.scale <= 0 {
+		if d.scale < 0 && s != "0" {
+			s += strings.Repeat("0", int(-d.scale))
+		}
+		return s
+	}
+	neg := s[0] == '-'
+	if neg {
+		s = s[1:]
+	}
+	if n := int(d.scale) + 1 - len(s); n > 0 {
+		s = strings.Repeat("0", n) + s
+	}
+	s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *Decimal) Scan(src interface{}) (err error) {
+	switch x := src.(type) {
+	case nil:
+		*d = Decimal{}
+	case int64:
+		*d = NewDecimal(x, 0)
+	case float64:
+		*d, err = ParseDecimal(strconv.FormatFloat(x, 'f', -1, 64))
+	case []byte:
+		*d, err = ParseDecimal(string(x))
+	case string:
+		*d, err = ParseDecimal(x)
+	default:
+		err = fmt.Errorf("huge: decimal scan unsupported: %T", src)
+	}
+	return
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(b []byte) (err error) {
+	*d, err = ParseDecimal(string(b))
+	return
+}
+
+// MarshalJSON as string to keep the precision.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON of string or number.
+func (d *Decimal) UnmarshalJSON(b []byte) (err error) {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if len(b) > 1 && b[0] == '"' {
+		b = b[1 : len(b)-1]
+	}
+	*d, err = ParseDecimal(string(b))
+	return
+}
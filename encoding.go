@@ -0,0 +1,144 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Encoding of a named field option by RegisterEncoding, like msgpack, cbor or yaml.
+type Encoding struct {
+	// Binary column type if true, else text.
+	Binary    bool
+	Marshal   func(interface{}) ([]byte, error)
+	Unmarshal func([]byte, interface{}) error
+}
+
+// Compression of encoded payloads by a named field option after the encoding,
+// like huge:",json,gzip", always a binary column type.
+type Compression struct {
+	Compress   func([]byte) ([]byte, error)
+	Decompress func([]byte) ([]byte, error)
+}
+
+type codec struct {
+	e *Encoding
+	z *Compression
+}
+
+var em sync.RWMutex
+
+var encodings = make(map[string]*Encoding)
+
+var compressions = map[string]*Compression{
+	"gzip": {gzipCompress, gzipDecompress},
+}
+
+// RegisterEncoding e as option name, before tables using it are created.
+func RegisterEncoding(name string, e Encoding) {
+	if e.Marshal == nil || e.Unmarshal == nil {
+		panic("huge: nil")
+	}
+	em.Lock()
+	defer em.Unlock()
+	checkCodecName(name)
+	encodings[name] = &e
+}
+
+// RegisterCompression c as option name, like zstd, gzip is builtin.
+func RegisterCompression(name string, c Compression) {
+	if c.Compress == nil || c.Decompress == nil {
+		panic("huge: nil")
+	}
+	em.Lock()
+	defer em.Unlock()
+	checkCodecName(name)
+	compressions[name] = &c
+}
+
+func checkCodecName(name string) {
+	if _, ok := options[name]; ok || len(name) == 0 || strings.ContainsAny(name, ",:") {
+		panic("huge: invalid option name: " + name)
+	} else if _, err := strconv.Atoi(name); err == nil {
+		panic("huge: invalid option name: " + name)
+	} else if encodings[name] != nil || compressions[name] != nil {
+		panic("huge: duplicate option: " + name)
+	}
+}
+
+func codecOf(name string) (*Encoding, *Compression) {
+	em.RLock()
+	defer em.RUnlock()
+	return encodings[name], compressions[name]
+}
+
+func gzipCompress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	} else if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gzipDecompress(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// encode v of f, empty is not compressed, v must CanInterface except gob.
+func (f *Field) encode(v reflect.Value) (b []byte, err error) {
+	if f.Is(oGob) {
+		var buf bytes.Buffer
+		err = gob.NewEncoder(&buf).EncodeValue(v)
+		b = buf.Bytes()
+	} else if f.Is(oJSON) {
+		b, err = json.Marshal(v.Interface())
+	} else if f.Is(oXML) {
+		b, err = xml.Marshal(v.Interface())
+	} else if f.Is(oEncoding) {
+		b, err = f.c.e.Marshal(v.Interface())
+	} else {
+		panic(false)
+	}
+	if err == nil && f.c.z != nil && len(b) > 0 {
+		b, err = f.c.z.Compress(b)
+	}
+	return
+}
+
+// decode b of f into v, settable and addressable.
+func (f *Field) decode(b []byte, v reflect.Value) (err error) {
+	if f.c.z != nil && len(b) > 0 {
+		if b, err = f.c.z.Decompress(b); err != nil {
+			return
+		}
+	}
+	if f.Is(oGob) {
+		return gob.NewDecoder(bytes.NewReader(b)).DecodeValue(v)
+	} else if f.Is(oJSON) {
+		return json.Unmarshal(b, v.Addr().Interface())
+	} else if f.Is(oXML) {
+		return xml.Unmarshal(b, v.Addr().Interface())
+	} else if f.Is(oEncoding) {
+		return f.c.e.Unmarshal(b, v.Addr().Interface())
+	}
+	panic(false)
+}
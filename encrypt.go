@@ -0,0 +1,331 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/cxr29/huge/query"
+)
+
+var (
+	ErrNoKeyProvider = errors.New("huge: no key provider")
+	ErrCiphertext    = errors.New("huge: invalid ciphertext")
+)
+
+// KeyProvider of AES keys of 16, 24 or 32 bytes for the encrypt columns.
+type KeyProvider interface {
+	// Current key to encrypt.
+	Current() (id string, key []byte, err error)
+	// Key by id to decrypt, the id is stored with the ciphertext.
+	Key(id string) ([]byte, error)
+}
+
+// KeyLister is optional for Eq of deterministic columns to match the ciphertexts of all keys while rotating.
+type KeyLister interface {
+	IDs() ([]string, error)
+}
+
+// KeyRing is a static KeyProvider and KeyLister.
+type KeyRing struct {
+	ID   string
+	Keys map[string][]byte
+}
+
+func (r KeyRing) Current() (string, []byte, error) {
+	k, err := r.Key(r.ID)
+	return r.ID, k, err
+}
+
+func (r KeyRing) Key(id string) ([]byte, error) {
+	if k, ok := r.Keys[id]; ok {
+		return k, nil
+	}
+	return nil, errors.New("huge: key not found: " + id)
+}
+
+func (r KeyRing) IDs() ([]string, error) {
+	a := make([]string, 0, len(r.Keys))
+	a = append(a, r.ID)
+	for k := range r.Keys {
+		if k != r.ID {
+			a = append(a, k)
+		}
+	}
+	return a, nil
+}
+
+var km sync.RWMutex
+
+var keyProvider KeyProvider
+
+// SetKeyProvider for the encrypt columns.
+func SetKeyProvider(p KeyProvider) {
+	km.Lock()
+	keyProvider = p
+	km.Unlock()
+}
+
+func getKeyProvider() (KeyProvider, error) {
+	km.RLock()
+	p := keyProvider
+	km.RUnlock()
+	if p == nil {
+		return nil, ErrNoKeyProvider
+	}
+	return p, nil
+}
+
+// seal b of the column x by AES-GCM as version 1, length of id, id, nonce and ciphertext,
+// the id and x are authenticated, the nonce is derived from x and b if deterministic.
+func seal(id string, key, b []byte, x string, deterministic bool) ([]byte, error) {
+	if len(id) > 255 {
+		return nil, errors.New("huge: key id too long")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	g, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, g.NonceSize())
+	if deterministic {
+		m := hmac.New(sha256.New, key)
+		m.Write([]byte("huge deterministic nonce"))
+		k := m.Sum(nil)
+		m = hmac.New(sha256.New, k)
+		m.Write([]byte(x))
+		m.Write([]byte{0})
+		m.Write(b)
+		copy(nonce, m.Sum(nil))
+	} else if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	c := make([]byte, 0, 2+len(id)+len(nonce)+len(b)+g.Overhead())
+	c = append(c, 1, byte(len(id)))
+	c = append(c, id...)
+	c = append(c, nonce...)
+	return g.Seal(c, nonce, b, aad(id, x)), nil
+}
+
+// aad of the key id and the column x, a ciphertext does not open in another column.
+func aad(id, x string) []byte {
+	return []byte(id + "\x00" + x)
+}
+
+func unseal(b []byte, x string) ([]byte, error) {
+	if len(b) < 2 || b[0] != 1 || len(b) < 2+int(b[1]) {
+		return nil, ErrCiphertext
+	}
+	id := string(b[2 : 2+b[1]])
+	b = b[2+len(id):]
+	p, err := getKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.Key(id)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	g, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < g.NonceSize() {
+		return nil, ErrCiphertext
+	}
+	return g.Open(nil, b[:g.NonceSize()], b[g.NonceSize():], aad(id, x))
+}
+
+// plaintext of i, string, bytes or encoded.
+func plaintext(i interface{}) ([]byte, bool) {
+	switch x := i.(type) {
+	case []byte:
+		return x, true
+	case string:
+		return []byte(x), true
+	}
+	v := reflect.ValueOf(i)
+	switch v.Kind() {
+	case reflect.String:
+		return []byte(v.String()), true
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), true
+		}
+	}
+	return nil, false
+}
+
+// encrypt the converted value i of c by the current key, nil is NULL.
+func (c *Column) encrypt(i interface{}) (interface{}, error) {
+	if i == nil {
+		return nil, nil
+	}
+	b, ok := plaintext(i)
+	if !ok {
+		return nil, c.errGet()
+	}
+	p, err := getKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+	id, key, err := p.Current()
+	if err != nil {
+		return nil, err
+	}
+	return seal(id, key, b, c.aad(), c.last().Is(oDeterministic))
+}
+
+// aad of c by the table and column names, renaming either needs to encrypt again.
+func (c *Column) aad() string {
+	return c.t.Name + "." + c.Name
+}
+
+// decrypt b into v of c, NULL sets the zero value.
+func (c *Column) decrypt(b []byte, v reflect.Value) error {
+	f := c.last()
+	if b == nil {
+		v.Set(reflect.Zero(f.t))
+		return nil
+	}
+	b, err := unseal(b, c.aad())
+	if err != nil {
+		return err
+	}
+	if f.IsEncoding() {
+		return f.decode(b, v)
+	} else if f.t.Kind() == reflect.String {
+		v.SetString(string(b))
+	} else {
+		v.SetBytes(b)
+	}
+	return nil
+}
+
+// Eq of i of the field type is encrypted on deterministic columns,
+// by all keys of the KeyLister to match while rotating, Date is String on native DATE columns.
+func (c *Column) Eq(i interface{}) query.Condition {
+	if c.isEncrypted(i) {
+		return query.C("?", encrypted{c, []interface{}{i}})
+	}
+	return c.Operand.Eq(c.date(i))
+}
+
+// Ne of i encrypted like Eq.
+func (c *Column) Ne(i interface{}) query.Condition {
+	if c.isEncrypted(i) {
+		return query.C("?", encrypted{c, []interface{}{i}}).Not()
+	}
+	return c.Operand.Ne(c.date(i))
+}
+
+// In of a encrypted like Eq if all of the field type.
+func (c *Column) In(a ...interface{}) query.Condition {
+	if len(a) > 0 && c.last().Is(oEncrypt) {
+		n := 0
+		for _, i := range a {
+			if c.isEncrypted(i) {
+				n++
+			}
+		}
+		if n == len(a) {
+			return query.C("?", encrypted{c, a})
+		}
+	}
+	if c.last().Is(oDate) {
+		b := make([]interface{}, len(a))
+		for k, i := range a {
+			b[k] = c.date(i)
+		}
+		a = b
+	}
+	return c.Operand.In(a...)
+}
+
+// isEncrypted if i of the field type of the encrypted column c.
+func (c *Column) isEncrypted(i interface{}) bool {
+	f := c.last()
+	return f.Is(oEncrypt) && i != nil && reflect.TypeOf(i) == f.Type()
+}
+
+// unordered of the encrypted column c by the ciphertexts.
+func (c *Column) unordered() query.Condition {
+	return query.C("?", encrypted{c, nil})
+}
+
+// encrypted a of c, or an error if nil.
+type encrypted struct {
+	c *Column
+	a []interface{}
+}
+
+func (e encrypted) Expand(s query.Starter, i int) (string, []interface{}, error) {
+	if e.a == nil {
+		return "", nil, e.c.err("encrypted not ordered")
+	} else if !e.c.last().Is(oDeterministic) {
+		return "", nil, e.c.err("not deterministic")
+	}
+	d := make([][]byte, len(e.a))
+	for k, j := range e.a {
+		x, err := e.c.plain(false, true, reflect.ValueOf(j))
+		if err != nil {
+			return "", nil, err
+		}
+		b, ok := plaintext(x)
+		if !ok {
+			return "", nil, e.c.errGet()
+		}
+		d[k] = b
+	}
+	p, err := getKeyProvider()
+	if err != nil {
+		return "", nil, err
+	}
+	var ids []string
+	if l, ok := p.(KeyLister); ok {
+		if ids, err = l.IDs(); err != nil {
+			return "", nil, err
+		}
+	} else {
+		id, _, err := p.Current()
+		if err != nil {
+			return "", nil, err
+		}
+		ids = []string{id}
+	}
+	a := make([]interface{}, 0, len(ids)*len(d))
+	for _, id := range ids {
+		key, err := p.Key(id)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, b := range d {
+			x, err := seal(id, key, b, e.c.aad(), true)
+			if err != nil {
+				return "", nil, err
+			}
+			a = append(a, x)
+		}
+	}
+	if len(a) == 1 {
+		return e.c.Operand.Eq(a[0]).Expand(s, i)
+	}
+	return e.c.Operand.In(a...).Expand(s, i)
+}
@@ -0,0 +1,352 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Fake is a database/sql driver for testing without a server,
+// it records every statement and matches them with the expectations in order.
+type Fake struct {
+	mu    sync.Mutex
+	a     []*FakeExpect
+	i     int
+	calls []FakeCall
+	err   error
+}
+
+type FakeCall struct {
+	SQL  string
+	Args []driver.Value
+}
+
+type FakeExpect struct {
+	b       byte
+	s       string
+	r       *regexp.Regexp
+	args    []driver.Value
+	any     bool
+	columns []string
+	rows    [][]driver.Value
+	id, n   int64
+	err     error
+}
+
+func NewFake() *Fake {
+	return new(Fake)
+}
+
+// Open Huge of the driver name dialect on the fake.
+func (f *Fake) Open(driverName string) (h Huge) {
+	h.Querier = sql.OpenDB(fakeConnector{f})
+	h.dialect(driverName)
+	return
+}
+
+func (f *Fake) expect(b byte, s string) *FakeExpect {
+	e := &FakeExpect{b: b, s: s, any: true}
+	switch b {
+	case 'n':
+		e.s = normalize(s)
+	case 'r':
+		e.r = regexp.MustCompile(s)
+	}
+	f.mu.Lock()
+	f.a = append(f.a, e)
+	f.mu.Unlock()
+	return e
+}
+
+// Expect the exact SQL.
+func (f *Fake) Expect(s string) *FakeExpect {
+	return f.expect('e', s)
+}
+
+// ExpectRegexp SQL matches the regular expression.
+func (f *Fake) ExpectRegexp(s string) *FakeExpect {
+	return f.expect('r', s)
+}
+
+// ExpectNormalized SQL equals ignoring case, spaces, identifier quotes and parameter styles.
+func (f *Fake) ExpectNormalized(s string) *FakeExpect {
+	return f.expect('n', s)
+}
+
+// Args expected, any if not given.
+func (e *FakeExpect) Args(a ...interface{}) *FakeExpect {
+	e.any = false
+	e.args = make([]driver.Value, len(a))
+	for i, j := range a {
+		v, err := driver.DefaultParameterConverter.ConvertValue(j)
+		if err != nil {
+			panic(err)
+		}
+		e.args[i] = v
+	}
+	return e
+}
+
+// Result of Exec.
+func (e *FakeExpect) Result(lastInsertId, rowsAffected int64) *FakeExpect {
+	e.id, e.n = lastInsertId, rowsAffected
+	return e
+}
+
+// Rows of Query, each row has a value per column.
+func (e *FakeExpect) Rows(columns []string, rows ...[]interface{}) *FakeExpect {
+	e.columns = columns
+	e.rows = make([][]driver.Value, len(rows))
+	for i, a := range rows {
+		if len(a) != len(columns) {
+			panic("huge: length")
+		}
+		e.rows[i] = make([]driver.Value, len(a))
+		for j, k := range a {
+			v, err := driver.DefaultParameterConverter.ConvertValue(k)
+			if err != nil {
+				panic(err)
+			}
+			e.rows[i][j] = v
+		}
+	}
+	return e
+}
+
+// Error of Exec or Query.
+func (e *FakeExpect) Error(err error) *FakeExpect {
+	e.err = err
+	return e
+}
+
+func (e *FakeExpect) match(s string, a []driver.Value) bool {
+	switch e.b {
+	case 'e':
+		if s != e.s {
+			return false
+		}
+	case 'n':
+		if normalize(s) != e.s {
+			return false
+		}
+	case 'r':
+		if !e.r.MatchString(s) {
+			return false
+		}
+	default:
+		panic(false)
+	}
+	if e.any {
+		return true
+	} else if len(a) != len(e.args) {
+		return false
+	}
+	for i, v := range a {
+		if !reflect.DeepEqual(v, e.args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *FakeExpect) String() string {
+	if e.any {
+		return e.s
+	}
+	return fmt.Sprint(e.s, " ", e.args)
+}
+
+var normalizeRegexp = regexp.MustCompile(`(\$|\?|@p)\d+`)
+
+// bracketRegexp of SQL Server quoted identifiers, not subscripts like a[1].
+var bracketRegexp = regexp.MustCompile(`(^|[^\w\])])\[([^\[\]]+)\]`)
+
+func normalize(s string) string {
+	s = normalizeRegexp.ReplaceAllString(s, "?")
+	s = bracketRegexp.ReplaceAllString(s, "$1$2")
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '"', '`':
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	return strings.TrimSuffix(s, ";")
+}
+
+func (f *Fake) call(s string, a []driver.Value) (*FakeExpect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, FakeCall{s, a})
+	if f.i < len(f.a) {
+		if e := f.a[f.i]; e.match(s, a) {
+			f.i++
+			return e, e.err
+		} else {
+			err := fmt.Errorf("huge: fake: expected %v but was %s %v", e, s, a)
+			if f.err == nil {
+				f.err = err
+			}
+			return nil, err
+		}
+	}
+	err := fmt.Errorf("huge: fake: unexpected %s %v", s, a)
+	if f.err == nil {
+		f.err = err
+	}
+	return nil, err
+}
+
+// Calls recorded.
+func (f *Fake) Calls() []FakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a := make([]FakeCall, len(f.calls))
+	copy(a, f.calls)
+	return a
+}
+
+// Err of the first mismatch or unmet expectation.
+func (f *Fake) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	} else if f.i < len(f.a) {
+		return fmt.Errorf("huge: fake: unmet %v", f.a[f.i])
+	}
+	return nil
+}
+
+// Reset expectations and calls.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	f.a, f.i, f.calls, f.err = nil, 0, nil, nil
+	f.mu.Unlock()
+}
+
+type fakeConnector struct {
+	f *Fake
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn(c), nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver(c)
+}
+
+type fakeDriver struct {
+	f *Fake
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return fakeConn(d), nil
+}
+
+type fakeConn struct {
+	f *Fake
+}
+
+func (c fakeConn) Prepare(s string) (driver.Stmt, error) {
+	return fakeStmt{c.f, s}, nil
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx(c), nil
+}
+
+type fakeTx struct {
+	f *Fake
+}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeStmt struct {
+	f *Fake
+	s string
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeStmt) Exec(a []driver.Value) (driver.Result, error) {
+	e, err := s.f.call(s.s, a)
+	if err != nil {
+		return nil, err
+	}
+	return fakeResult{e.id, e.n}, nil
+}
+
+func (s fakeStmt) Query(a []driver.Value) (driver.Rows, error) {
+	e, err := s.f.call(s.s, a)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{e.columns, e.rows}, nil
+}
+
+type fakeResult struct {
+	id, n int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return r.id, nil
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return r.n, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	r.rows = nil
+	return nil
+}
+
+func (r *fakeRows) Next(a []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	} else if len(a) != len(r.rows[0]) {
+		return errors.New("huge: fake: length")
+	}
+	copy(a, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
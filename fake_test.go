@@ -0,0 +1,332 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/cxr29/huge/query"
+)
+
+type Post struct {
+	Id      int64
+	Title   string
+	Version int `huge:",version"`
+}
+
+func TestFakeNormalize(t *testing.T) {
+	for _, a := range [][2]string{
+		{`SELECT "Id" FROM "Post" WHERE "Id" = $1`, "select id from post where id = ?"},
+		{"SELECT `Id` FROM `Post` WHERE `Id` = ?", "select id from post where id = ?"},
+		{"SELECT [Id] FROM dbo.[Post] WHERE ([Id] = @p1);", "select id from dbo.post where (id = ?)"},
+		{"SELECT a[1] FROM t", "select a[1] from t"},
+		{"SELECT a1  FROM\n t", "select a1 from t"},
+	} {
+		if s := normalize(a[0]); s != a[1] {
+			t.Errorf("normalize %q: %q, want %q", a[0], s, a[1])
+		}
+	}
+	if normalize("SELECT a[1] FROM t") == normalize("SELECT a1 FROM t") {
+		t.Error("normalize subscript")
+	}
+}
+
+func TestFakeCRUD(t *testing.T) {
+	f := NewFake()
+	h := f.Open("postgres")
+	f.ExpectNormalized(`INSERT INTO post (title, version) VALUES ($1, $2) RETURNING id`).
+		Args("a", 1).Rows([]string{"id"}, []interface{}{1})
+	f.ExpectNormalized(`SELECT id, title, version FROM post WHERE (id = $1) AND (version = $2)`).
+		Args(1, 1).Rows([]string{"id", "title", "version"}, []interface{}{1, "b", 1})
+	f.ExpectNormalized(`UPDATE post SET title = $1, version = version + 1 WHERE (id = $2) AND (version = $3) RETURNING version`).
+		Args("c", 1, 1).Rows([]string{"version"}, []interface{}{2})
+	f.ExpectNormalized(`DELETE FROM post WHERE (id = $1) AND (version = $2)`).
+		Args(1, 2).Result(0, 1)
+	p := &Post{Title: "a"}
+	if _, err := h.Create(p); err != nil {
+		t.Fatal(err)
+	} else if p.Id != 1 || p.Version != 1 {
+		t.Fatalf("create: %+v", p)
+	}
+	if ok, err := h.Read(p); err != nil || ok != true {
+		t.Fatal(ok, err)
+	} else if p.Title != "b" {
+		t.Fatalf("read: %+v", p)
+	}
+	p.Title = "c"
+	if ok, err := h.Update(p); err != nil || ok != true {
+		t.Fatal(ok, err)
+	} else if p.Version != 2 {
+		t.Fatalf("update: %+v", p)
+	}
+	if ok, err := h.Delete(p); err != nil || ok != true {
+		t.Fatal(ok, err)
+	}
+	if err := f.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(f.Calls()); n != 4 {
+		t.Fatalf("calls: %d", n)
+	}
+}
+
+func TestFakeVersionConflict(t *testing.T) {
+	f := NewFake()
+	h := f.Open("mysql")
+	f.ExpectNormalized("UPDATE post SET title = ?, version = version + 1 WHERE (id = ?) AND (version = ?)").
+		Args("a", 1, 3).Result(0, 0)
+	f.ExpectNormalized("DELETE FROM post WHERE (id = ?) AND (version = ?)").
+		Args(1, 3).Result(0, 0)
+	p := &Post{Id: 1, Title: "a", Version: 3}
+	if ok, err := h.Update(p); err != nil || ok != false {
+		t.Fatal(ok, err)
+	} else if p.Version != 3 {
+		t.Fatalf("update: %+v", p)
+	}
+	if ok, err := h.Delete(p); err != nil || ok != false {
+		t.Fatal(ok, err)
+	}
+	if err := f.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFakeMismatch(t *testing.T) {
+	f := NewFake()
+	h := f.Open("mysql")
+	e := errors.New("deadlock")
+	f.ExpectRegexp("^DELETE").Error(e)
+	if _, err := h.Delete(&Post{Id: 1, Version: 1}); err != e {
+		t.Fatal(err)
+	}
+	if _, err := h.Create(&Post{}); err == nil {
+		t.Fatal("unexpected create")
+	}
+	if f.Err() == nil {
+		t.Fatal("no error")
+	}
+	f.Reset()
+	f.Expect("SELECT 1")
+	if f.Err() == nil {
+		t.Fatal("unmet")
+	}
+}
+
+type Draft struct {
+	Snapshot
+	Id    int64
+	Title string
+}
+
+func TestFakeSnapshotCopy(t *testing.T) {
+	f := NewFake()
+	h := f.Open("mysql")
+	f.ExpectNormalized("SELECT id, title FROM draft WHERE id = ?").
+		Args(1).Rows([]string{"id", "title"}, []interface{}{1, "a"})
+	f.ExpectNormalized("UPDATE draft SET title = ? WHERE id = ?").
+		Args("b", 1).Result(0, 1)
+	p := &Draft{Id: 1}
+	if ok, err := h.Read(p); err != nil || ok != true {
+		t.Fatal(ok, err)
+	}
+	q := *p
+	p.Title = "b"
+	if ok, err := h.Update(p, "Title"); err != nil || ok != true {
+		t.Fatal(ok, err)
+	}
+	x := NewTable(q)
+	if a, ok, err := x.changed(reflect.ValueOf(&q).Elem(), x.a); err != nil || !ok || len(a) != 0 {
+		t.Fatal(a, ok, err)
+	}
+	if err := f.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFakeUpdateRows(t *testing.T) {
+	f := NewFake()
+	h := f.Open("postgres")
+	f.ExpectRegexp(`^UPDATE "Post" SET .* FROM \(VALUES .*\) .* RETURNING "Id"$`).
+		Args(1, 1, "a", 2, 3, "b").Rows([]string{"id"}, []interface{}{2})
+	a := []Post{{Id: 1, Title: "a", Version: 1}, {Id: 2, Title: "b", Version: 3}}
+	if m, err := h.UpdateRows(a); err != nil {
+		t.Fatal(err)
+	} else if m := m.(map[int]struct{}); len(m) != 1 {
+		t.Fatal(m)
+	} else if _, ok := m[1]; !ok || a[0].Version != 1 || a[1].Version != 4 {
+		t.Fatal(m, a)
+	}
+	if err := f.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	f = NewFake()
+	h = f.Open("mysql")
+	f.ExpectNormalized("UPDATE post SET title = ?, version = version + 1 WHERE (id = ?) AND (version = ?)").
+		Args("a", 1, 1).Result(0, 1)
+	f.ExpectNormalized("UPDATE post SET title = ?, version = version + 1 WHERE (id = ?) AND (version = ?)").
+		Args("b", 2, 3).Result(0, 0)
+	a = []Post{{Id: 1, Title: "a", Version: 1}, {Id: 2, Title: "b", Version: 3}}
+	if m, err := h.UpdateRows(a); err != nil {
+		t.Fatal(err)
+	} else if m := m.(map[int]struct{}); len(m) != 1 {
+		t.Fatal(m)
+	} else if _, ok := m[0]; !ok || a[0].Version != 2 || a[1].Version != 3 {
+		t.Fatal(m, a)
+	}
+	if err := f.Err(); err != nil {
+		t.Fatal(err)
+	} else if n := len(f.Calls()); n != 2 {
+		t.Fatalf("calls: %d", n)
+	}
+}
+
+func TestFakeRowsAffectedUnsupported(t *testing.T) {
+	f := NewFake()
+	h := f.Open("clickhouse")
+	p := &Post{Id: 1, Title: "a", Version: 1}
+	if _, err := h.Update(p); err == nil {
+		t.Fatal("update")
+	} else if _, err = h.Delete(p); err == nil {
+		t.Fatal("delete")
+	} else if _, err = h.UpdateBy(1, p); err == nil {
+		t.Fatal("update by")
+	} else if _, err = h.UpdateRows([]Post{*p}); err == nil {
+		t.Fatal("update rows")
+	} else if p.Version != 1 {
+		t.Fatalf("version: %+v", p)
+	} else if n := len(f.Calls()); n != 0 {
+		t.Fatalf("calls: %d", n)
+	}
+}
+
+type Doc struct {
+	Id      int64
+	Meta    map[string]interface{} `huge:",json"`
+	Version int                    `huge:",version"`
+}
+
+func TestFakeUpdateJSON(t *testing.T) {
+	f := NewFake()
+	h := f.Open("postgres")
+	f.ExpectNormalized(`UPDATE doc SET meta = jsonb_set(COALESCE(meta, '{}'), '{a,b}', CAST($1 AS JSONB)), version = version + 1 WHERE (id = $2) AND (version = $3)`).
+		Args("2", 1, 1).Result(0, 1)
+	f.ExpectNormalized(`UPDATE doc SET meta = jsonb_set(COALESCE(meta, '{}'), '{c}', CAST($1 AS JSONB)), version = version + 1 WHERE (id = $2) AND (version = $3)`).
+		Args(`"x"`, 1, 2).Result(0, 1)
+	d := &Doc{Id: 1, Meta: map[string]interface{}{"a": map[string]interface{}{"b": 1}}, Version: 1}
+	if ok, err := h.UpdateJSON(d, "Meta", 2, "a", "b"); err != nil || ok != true {
+		t.Fatal(ok, err)
+	} else if fmt.Sprint(d.Meta) != "map[a:map[b:2]]" || d.Version != 2 {
+		t.Fatalf("update: %+v", d)
+	}
+	d.Meta = nil
+	if ok, err := h.UpdateJSON(d, "Meta", "x", "c"); err != nil || ok != true {
+		t.Fatal(ok, err)
+	} else if fmt.Sprint(d.Meta) != "map[c:x]" || d.Version != 3 {
+		t.Fatalf("update: %+v", d)
+	}
+	if err := f.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type Event struct {
+	Id  int64
+	Day Date `huge:",date"`
+}
+
+func TestFakeDateInvalid(t *testing.T) {
+	f := NewFake()
+	h := f.Open("postgres")
+	f.ExpectNormalized(`INSERT INTO event (day) VALUES ($1) RETURNING id`).
+		Args(nil).Rows([]string{"id"}, []interface{}{1})
+	if _, err := h.Create(&Event{}); err != nil {
+		t.Fatal(err)
+	}
+	c := NewTable(Event{}).Find("Day")
+	if s, a, err := h.Expand(c.Ge(Date(0))); err != nil || len(a) != 1 || a[0] != nil {
+		t.Fatal(s, a, err)
+	} else if s, a, err = h.Expand(c.Eq(NewDate(2024, 1, 2))); err != nil || len(a) != 1 || a[0] != "2024-01-02" {
+		t.Fatal(s, a, err)
+	}
+	if err := f.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type Secret struct {
+	Id    int64
+	Email string `huge:",encrypt,deterministic"`
+}
+
+func TestFakeEncryptedConditions(t *testing.T) {
+	SetKeyProvider(KeyRing{"a", map[string][]byte{"a": make([]byte, 32), "b": make([]byte, 32)}})
+	defer SetKeyProvider(nil)
+	h := NewFake().Open("mysql")
+	c := NewTable(Secret{}).Find("Email")
+	e, err := c.encrypt("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, a, err := h.Expand(c.In("x", "y")); err != nil || s != "Email IN (?, ?, ?, ?)" || !reflect.DeepEqual(a[0], e) {
+		t.Fatal(s, a, err)
+	}
+	if s, a, err := h.Expand(c.Ne("x")); err != nil || s != "NOT (Email IN (?, ?))" || len(a) != 2 {
+		t.Fatal(s, a, err)
+	}
+	if _, _, err := h.Expand(c.Lt("x")); err == nil {
+		t.Fatal("lt")
+	}
+}
+
+type Account struct {
+	Id     int64
+	Name   string
+	Status string `huge:",default"`
+}
+
+func TestFakeDetect(t *testing.T) {
+	f := NewFake()
+	h := f.Open("sqlite3")
+	if _, err := h.Create(&Account{Name: "a"}); err == nil {
+		t.Fatal("undetected")
+	}
+	f.Expect("SELECT sqlite_version()").Rows([]string{"v"}, []interface{}{"3.45.1"})
+	f.ExpectNormalized("INSERT INTO account (name) VALUES (?) RETURNING id, status").
+		Args("a").Rows([]string{"id", "status"}, []interface{}{1, "new"})
+	if err := h.Detect(); err != nil {
+		t.Fatal(err)
+	}
+	a := &Account{Name: "a"}
+	if _, err := h.Create(a); err != nil {
+		t.Fatal(err)
+	} else if a.Id != 1 || a.Status != "new" {
+		t.Fatalf("create: %+v", a)
+	}
+	if err := f.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type Item struct {
+	Id    int64
+	Name  string
+	Price *int64
+}
+
+func TestFakePaginateNullable(t *testing.T) {
+	h := NewFake().Open("postgres")
+	q := query.Q(query.Select(), query.From("Item"))
+	h.Paginate(q, Item{}, 10, "-Name")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("nullable")
+		}
+	}()
+	h.Paginate(q, Item{}, 10, "Price")
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/cxr29/huge/query"
+)
+
+func (t *Table) fulltext() (a Columns) {
+	for _, c := range t.a {
+		if !c.isMany() && c.last().Is(oFulltext) {
+			a = append(a, c)
+		}
+	}
+	return
+}
+
+// fts table of SQLite for the fulltext columns, synchronized by triggers.
+func (t *Table) fts() string {
+	return t.Name + "_fts"
+}
+
+// rowid of the fts table, the integer primary key or the implicit rowid.
+func (t *Table) rowid() *Column {
+	if c := t.PrimaryKey(); c != nil && isIntegers(c.last().Type()) {
+		return c
+	}
+	return nil
+}
+
+func (t *Table) createFulltext(s query.Starter, tableName string, a Columns, ifNotExists bool) (string, error) {
+	var b bytes.Buffer
+	switch s.Dialect() {
+	case "postgres":
+		name := s.Quote(t.Name + "_fulltext")
+		if len(name) == 0 {
+			return "", t.errUnsupported()
+		}
+		o := make([]query.Operand, len(a))
+		for i, c := range a {
+			o[i] = c.Operand
+		}
+		d, _, err := query.Expand(query.Document(o), false, s, 1)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString("CREATE INDEX ")
+		if ifNotExists {
+			b.WriteString("IF NOT EXISTS ")
+		}
+		b.WriteString(name)
+		b.WriteString(" ON ")
+		b.WriteString(tableName)
+		b.WriteString(" USING GIN (")
+		b.WriteString(d)
+		b.WriteString(");\n")
+	case "sqlite3":
+		fts := s.Quote(t.fts())
+		if len(fts) == 0 {
+			return "", t.errUnsupported()
+		}
+		rowid, content := "rowid", "rowid"
+		if c := t.rowid(); c != nil {
+			rowid, content = s.Quote(c.Name), c.Name
+		}
+		names := make([]string, len(a))
+		olds := make([]string, len(a))
+		news := make([]string, len(a))
+		for i, c := range a {
+			names[i] = s.Quote(c.Name)
+			olds[i] = "old." + names[i]
+			news[i] = "new." + names[i]
+		}
+		exists := ""
+		if ifNotExists {
+			exists = "IF NOT EXISTS "
+		}
+		columns := strings.Join(names, ", ")
+		insert := "INSERT INTO " + fts + " (rowid, " + columns + ") VALUES (new." + rowid + ", " + strings.Join(news, ", ") + ");"
+		remove := "INSERT INTO " + fts + " (" + fts + ", rowid, " + columns + ") VALUES ('delete', old." + rowid + ", " + strings.Join(olds, ", ") + ");"
+		b.WriteString("CREATE VIRTUAL TABLE " + exists + fts + " USING fts5(" + columns +
+			", content=" + query.Quote(t.Name, '\'') + ", content_rowid=" + query.Quote(content, '\'') + ");\n")
+		for _, x := range [...][2]string{
+			{"ai", "AFTER INSERT ON " + tableName + " BEGIN " + insert},
+			{"ad", "AFTER DELETE ON " + tableName + " BEGIN " + remove},
+			{"au", "AFTER UPDATE ON " + tableName + " BEGIN " + remove + " " + insert},
+		} {
+			name := s.Quote(t.fts() + "_" + x[0])
+			if len(name) == 0 {
+				return "", t.errUnsupported()
+			}
+			b.WriteString("CREATE TRIGGER " + exists + name + " " + x[1] + " END;\n")
+		}
+	default:
+		return "", t.err("fulltext unsupported: " + s.Dialect())
+	}
+	return b.String(), nil
+}
+
+type fulltext struct {
+	t    *Table
+	r    bool
+	text string
+	mode int
+}
+
+func (f fulltext) Expand(s query.Starter, i int) (string, []interface{}, error) {
+	a := f.t.fulltext()
+	if len(a) == 0 {
+		return "", nil, f.t.err("no fulltext columns")
+	}
+	o := make([]query.Operand, len(a))
+	if s.Dialect() != "sqlite3" {
+		for j, c := range a {
+			o[j] = c.Operand
+		}
+		if f.r {
+			return query.Relevance(o, f.text, f.mode).Expand(s, i)
+		}
+		return query.Match(o, f.text, f.mode).Expand(s, i)
+	}
+	fts := f.t.fts()
+	for j, c := range a {
+		o[j] = query.IQ(fts, c.Name)
+	}
+	var rowid interface{} = query.Literal("rowid")
+	if c := f.t.rowid(); c != nil {
+		rowid = c.Qualifier()
+	}
+	if f.r {
+		return query.E("(SELECT ? FROM ? WHERE ? AND rowid = ?)",
+			query.Relevance(o, f.text, f.mode), query.Identifier(fts), query.Match(o, f.text, f.mode), rowid,
+		).Expand(s, i)
+	}
+	return query.E("? IN (SELECT rowid FROM ? WHERE ?)",
+		rowid, query.Identifier(fts), query.Match(o, f.text, f.mode),
+	).Expand(s, i)
+}
+
+// Match the fulltext columns against text in mode, on SQLite by the FTS5 table of CreateTable.
+func (t *Table) Match(text string, mode int) query.Condition {
+	return query.C("?", fulltext{t, false, text, mode})
+}
+
+// Relevance of Match for ordering, the higher the more relevant.
+func (t *Table) Relevance(text string, mode int) query.Expression {
+	return fulltext{t, true, text, mode}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+
+package huge
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/cxr29/huge/query"
+)
+
+// TableOf struct T returns error instead of panic.
+func TableOf[T any]() (*Table, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("huge: type unsupported: %v", t)
+	}
+	return tableOf(t)
+}
+
+func rowsOf[T any](r *Rows) error {
+	_, err := TableOf[T]()
+	if err != nil && r.err == nil {
+		r.logWarning(r.rows.Close())
+	}
+	return err
+}
+
+// Get T by primary key, ErrNoRows if not found.
+func Get[T any](h Huge, pk interface{}, columns ...string) (*T, error) {
+	t, err := TableOf[T]()
+	if err != nil {
+		return nil, err
+	}
+	c := t.PrimaryKey()
+	if c == nil {
+		return nil, t.errNoPrimaryKey()
+	}
+	v := reflect.ValueOf(pk)
+	if !v.IsValid() {
+		return nil, c.err("type mismatch")
+	} else if t := c.last().t; v.Type() != t {
+		if !convertible(v, t) {
+			return nil, c.err("type mismatch")
+		}
+		v = v.Convert(t)
+	}
+	k, err := c.convert(true, true, v)
+	if err != nil {
+		return nil, err
+	}
+	a := t.Filter(columns...)
+	if a.Empty() {
+		return nil, t.errNoColumns()
+	}
+	row := new(T)
+	ok, err := h.Q(
+		query.Select(a.Strings()...), query.From(t.Name), query.Where(c.Eq(k)),
+	).One(row)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrNoRows
+	}
+	return row, nil
+}
+
+// Find []*T where all conditions.
+func Find[T any](h Huge, conditions ...query.Condition) ([]*T, error) {
+	t, err := TableOf[T]()
+	if err != nil {
+		return nil, err
+	}
+	return All[T](h.Q(query.Select(), query.From(t.Name), query.Where(conditions...)))
+}
+
+// CreateAll returns the number of rows created.
+func CreateAll[T any](h Huge, rows []*T) (int, error) {
+	if _, err := TableOf[T](); err != nil {
+		return 0, err
+	}
+	i, err := h.Create(rows)
+	n, _ := i.(int)
+	return n, err
+}
+
+// One T, ErrNoRows if no rows, and Close.
+func One[T any](r *Rows) (*T, error) {
+	if err := rowsOf[T](r); err != nil {
+		return nil, err
+	}
+	row := new(T)
+	if ok, err := r.One(row); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrNoRows
+	}
+	return row, nil
+}
+
+// All []*T and Close.
+func All[T any](r *Rows) ([]*T, error) {
+	if err := rowsOf[T](r); err != nil {
+		return nil, err
+	}
+	var a []*T
+	if err := r.All(&a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Each row scan into a new T and call f as Rows.Each.
+func Each[T any](r *Rows, f func(*T) error) error {
+	if err := rowsOf[T](r); err != nil {
+		return err
+	}
+	return r.Each(f)
+}
@@ -7,10 +7,10 @@ package huge
 import (
 	"database/sql"
 	"database/sql/driver"
+	"fmt"
 	"time"
 
 	"github.com/cxr29/huge/query"
-	"github.com/cxr29/log"
 )
 
 var (
@@ -26,14 +26,35 @@ type Querier interface {
 }
 
 type Huge struct {
-	Starter  query.Starter
-	Querier  Querier
-	DealName func(string) string
-	TimePrec int
+	Starter   query.Starter
+	Querier   Querier
+	DealName  func(string) string
+	TimePrec  int
+	Clock     Clock
+	Location  *time.Location // of scanned times if not nil, then stored in UTC and naive times rejected
+	Logger    Logger
+	Redact    func(interface{}) interface{}
+	SlowQuery time.Duration
+
+	Interceptors []Interceptor
+
+	Replicas []*sql.DB
+	Balancer Balancer
+	session  *session
+
+	Stmts *StmtCache
+	db    *sql.DB
 }
 
+// Open without connecting, call Detect after for the version-aware features of SQLite and MySQL,
+// like RETURNING, else they are off and reading back default or generated columns errors.
 func Open(driverName, dataSourceName string) (h Huge, err error) {
 	h.Querier, err = sql.Open(driverName, dataSourceName)
+	h.dialect(driverName)
+	return
+}
+
+func (h *Huge) dialect(driverName string) {
 	switch driverName {
 	case "mysql":
 		h.Starter = query.MySQLStarter
@@ -44,15 +65,84 @@ func Open(driverName, dataSourceName string) (h Huge, err error) {
 	case "sqlite3":
 		h.Starter = query.SQLiteStarter
 		h.TimePrec = 9
+	case "sqlserver":
+		h.Starter = query.SQLServerStarter
+		h.TimePrec = 7
+	case "clickhouse":
+		h.Starter = query.ClickHouseStarter
+		h.TimePrec = 3
 	default:
 		h.Starter = query.StandardStarter
 		h.TimePrec = 6
 	}
+}
+
+// Detect the server version of SQLite and MySQL for version-aware features such as RETURNING.
+func (h *Huge) Detect() (err error) {
+	q := h.Primary().Querier
+	switch s := h.Starter.(type) {
+	case query.SQLite:
+		if err = q.QueryRow("SELECT sqlite_version()").Scan(&s.Version); err == nil {
+			h.Starter = s
+		}
+	case query.MySQL:
+		if err = q.QueryRow("SELECT VERSION()").Scan(&s.Version); err == nil {
+			h.Starter = s
+		}
+	}
 	return
 }
 
+// now of the Clock for the auto times, in Location if not nil.
+func (h Huge) now() time.Time {
+	var t time.Time
+	if h.Clock != nil {
+		t = h.Clock.Now()
+	} else {
+		t = time.Now()
+	}
+	if h.Location != nil {
+		return t.In(h.Location)
+	}
+	return t
+}
+
+// bind a of the registered types and times by the Location policy, a is modified.
+func (h Huge) bind(a []interface{}) ([]interface{}, error) {
+	a, err := values(a)
+	if err != nil {
+		return a, err
+	}
+	for k, i := range a {
+		var t time.Time
+		switch x := i.(type) {
+		case query.Array:
+			if h.Starter.Dialect() != "postgres" {
+				return nil, fmt.Errorf("huge: array unsupported: %T", x.V)
+			}
+			continue
+		case time.Time:
+			t = x
+		case *time.Time:
+			if x == nil {
+				continue
+			}
+			t = *x
+		default:
+			continue
+		}
+		if h.Location == nil {
+			continue
+		} else if t.Location() == time.Local && h.Location != time.Local && !t.IsZero() {
+			return nil, ErrNaiveTime
+		}
+		a[k] = t.UTC()
+	}
+	return a, nil
+}
+
 func (h Huge) Now() time.Time {
-	return h.LimitTime(time.Now())
+	return limitTime(h.now(), h.TimePrec)
 }
 func (h Huge) LimitTime(t time.Time) time.Time {
 	return LimitTime(t, h.TimePrec)
@@ -65,6 +155,11 @@ func (h Huge) mustDB() *sql.DB {
 	}
 	return db
 }
+func (h Huge) mustDBs() []*sql.DB {
+	a := make([]*sql.DB, 1, 1+len(h.Replicas))
+	a[0] = h.mustDB()
+	return append(a, h.Replicas...)
+}
 func (h Huge) mustTx() *sql.Tx {
 	tx, ok := h.Querier.(*sql.Tx)
 	if !ok {
@@ -73,17 +168,31 @@ func (h Huge) mustTx() *sql.Tx {
 	return tx
 }
 
-func (h Huge) Close() error {
-	return h.mustDB().Close()
+func (h Huge) Close() (err error) {
+	if h.Stmts != nil {
+		err = h.Stmts.Close()
+	}
+	for _, db := range h.mustDBs() {
+		if e := db.Close(); err == nil {
+			err = e
+		}
+	}
+	return
 }
 func (h Huge) SetConnMaxLifetime(d time.Duration) {
-	h.mustDB().SetConnMaxLifetime(d)
+	for _, db := range h.mustDBs() {
+		db.SetConnMaxLifetime(d)
+	}
 }
 func (h Huge) SetMaxIdleConns(n int) {
-	h.mustDB().SetMaxIdleConns(n)
+	for _, db := range h.mustDBs() {
+		db.SetMaxIdleConns(n)
+	}
 }
 func (h Huge) SetMaxOpenConns(n int) {
-	h.mustDB().SetMaxOpenConns(n)
+	for _, db := range h.mustDBs() {
+		db.SetMaxOpenConns(n)
+	}
 }
 func (h Huge) Driver() driver.Driver {
 	return h.mustDB().Driver()
@@ -92,44 +201,120 @@ func (h Huge) Stats() sql.DBStats {
 	return h.mustDB().Stats()
 }
 func (h Huge) Ping() error {
-	return h.mustDB().Ping()
+	for _, db := range h.mustDBs() {
+		if err := db.Ping(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (h Huge) Expand(q query.Expression) (string, []interface{}, error) {
 	s, a, err := query.Expand(q, false, h.Starter, 1)
-	log.Debugln(s, a)
-	log.ErrDebug(err)
+	if err == nil {
+		a, err = h.bind(a)
+	}
+	h.logExpand(s, a, err)
 	return s, a, err
 }
 func (h Huge) Exec(q query.Expression) (sql.Result, error) {
+	return h.exec("", q)
+}
+func (h Huge) exec(table string, q query.Expression) (r sql.Result, err error) {
 	s, a, err := h.Expand(q)
 	if err != nil {
 		return nil, err
 	}
-	return h.Querier.Exec(s, a...)
+	c := &Call{Op: OpExec, Table: table, SQL: s, Args: a}
+	err = h.intercept(c, func() (err error) {
+		defer h.logSlow(s, a, time.Now())
+		h.wrote()
+		if r, err = h.Querier.Exec(s, a...); err == nil && len(h.Interceptors) > 0 {
+			c.RowsAffected, _ = r.RowsAffected()
+		}
+		return
+	})
+	return
+}
+
+// updateSet by ALTER TABLE if the Starter lacks in place update.
+func (h Huge) updateSet(table string) (query.Expression, *query.QueryS) {
+	if query.Supports(h.Starter, query.FeatureUpdate) {
+		return query.Update(table), query.X.Set()
+	}
+	return query.AlterUpdate(table), query.X.Assign()
+}
+func (h Huge) deleteFrom(table string) query.Expression {
+	if query.Supports(h.Starter, query.FeatureUpdate) {
+		return query.Delete(table)
+	}
+	return query.AlterDelete(table)
+}
+
+// rowsAffected of r, or n submitted but not confirmed if the Starter can not tell.
+func (h Huge) rowsAffected(r sql.Result, n int64) (int64, error) {
+	if query.Supports(h.Starter, query.FeatureRowsAffected) {
+		return r.RowsAffected()
+	}
+	return n, nil
+}
+
+// checkVersion of t, unchecked if the Starter can not tell the rows affected.
+func (h Huge) checkVersion(t *Table) error {
+	if t.Version() != nil && !query.Supports(h.Starter, query.FeatureRowsAffected) {
+		return t.Version().err("version unchecked without rows affected")
+	}
+	return nil
 }
 func (h Huge) Prepare(q query.Expression) (*sql.Stmt, []interface{}, error) {
 	s, a, err := h.Expand(q)
 	if err != nil {
 		return nil, nil, err
 	}
-	p, err := h.Querier.Prepare(s)
+	p, err := h.prepare(h.Querier, "", s)
 	return p, a, err
 }
+func (h Huge) prepare(q Querier, table, s string) (p *sql.Stmt, err error) {
+	err = h.intercept(&Call{Op: OpPrepare, Table: table, SQL: s}, func() (err error) {
+		p, err = q.Prepare(s)
+		return
+	})
+	if err != nil && p != nil {
+		h.logWarning(p.Close())
+		p = nil
+	}
+	return
+}
 func (h Huge) Query(q query.Expression) *Rows {
 	var rows *sql.Rows
 	s, a, err := h.Expand(q)
 	if err == nil {
-		rows, err = h.Querier.Query(s, a...)
+		rows, err = h.query("", s, a)
 	}
-	return &Rows{err, rows, h.DealName}
+	return &Rows{err, rows, h.Logger, h.DealName, h.Location}
+}
+func (h Huge) query(table, s string, a []interface{}) (rows *sql.Rows, err error) {
+	err = h.intercept(&Call{Op: OpQuery, Table: table, SQL: s, Args: a}, func() (err error) {
+		defer h.logSlow(s, a, time.Now())
+		if !readOnly(s) {
+			h.wrote()
+		}
+		rows, err = h.reader(s).Query(s, a...)
+		return
+	})
+	if err != nil && rows != nil {
+		h.logWarning(rows.Close())
+		rows = nil
+	}
+	return
 }
 func (h Huge) Q(a ...query.Expression) *Rows {
 	return h.Query(query.Q(a...))
 }
 
 func (h Huge) Begin() (_ Huge, err error) {
-	h.Querier, err = h.mustDB().Begin()
+	h.db = h.mustDB()
+	h.Querier, err = h.db.Begin()
 	return h, err
 }
 func (h Huge) Commit() (err error) {
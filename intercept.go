@@ -0,0 +1,52 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"time"
+)
+
+type Op string
+
+const (
+	OpExec     Op = "exec"
+	OpPrepare  Op = "prepare"
+	OpQuery    Op = "query"
+	OpQueryRow Op = "query_row"
+)
+
+// Call is seen by interceptors, Duration, RowsAffected of exec and Err are set after next.
+type Call struct {
+	Op           Op
+	Table        string
+	SQL          string
+	Args         []interface{}
+	Duration     time.Duration
+	RowsAffected int64
+	Err          error
+}
+
+// Interceptor calls next to go on the chain and returns its error,
+// or returns an error without calling next to refuse the call.
+type Interceptor func(c *Call, next func() error) error
+
+func (h Huge) intercept(c *Call, f func() error) error {
+	if len(h.Interceptors) == 0 {
+		return f()
+	}
+	var next func(int) error
+	next = func(i int) error {
+		if i < len(h.Interceptors) {
+			return h.Interceptors[i](c, func() error {
+				return next(i + 1)
+			})
+		}
+		start := time.Now()
+		c.Err = f()
+		c.Duration = time.Since(start)
+		return c.Err
+	}
+	return next(0)
+}
@@ -5,12 +5,10 @@
 package huge
 
 import (
-	"database/sql"
 	"fmt"
 	"reflect"
 
 	"github.com/cxr29/huge/query"
-	"github.com/cxr29/log"
 )
 
 // Load T returns bool, []T returns int, map[]T returns map[]struct{}.
@@ -27,17 +25,17 @@ func (h Huge) Load(i interface{}) (interface{}, error) {
 	if values.Empty() {
 		return nil, t.errNoColumns()
 	}
-	s, _, err := h.Prepare(query.Q(query.Insert(t.Name), values))
+	s, err := h.prepareStmt(t.Name, query.Q(query.Insert(t.Name), values))
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
-		log.ErrWarning(s.Close())
+		h.logWarning(s.Close())
 	}()
 	return h.load(s, t, v)
 }
 
-func (h Huge) load(s *sql.Stmt, t *Table, v reflect.Value) (_ interface{}, err error) {
+func (h Huge) load(s *stmt, t *Table, v reflect.Value) (_ interface{}, err error) {
 	switch v.Kind() {
 	case reflect.Map:
 		m := reflect.MakeMap(reflect.MapOf(v.Type().Key(), typeEmpty))
@@ -63,7 +61,7 @@ func (h Huge) load(s *sql.Stmt, t *Table, v reflect.Value) (_ interface{}, err e
 	return err == nil, err
 }
 
-func (h Huge) load1(s *sql.Stmt, t *Table, v reflect.Value) error {
+func (h Huge) load1(s *stmt, t *Table, v reflect.Value) error {
 	if v.Kind() == reflect.Ptr {
 		if v.IsNil() {
 			return t.errNil()
@@ -85,7 +83,7 @@ func (h Huge) load1(s *sql.Stmt, t *Table, v reflect.Value) error {
 	if err != nil {
 		return err
 	}
-	n, err := r.RowsAffected()
+	n, err := h.rowsAffected(r, 1)
 	if err != nil {
 		return err
 	} else if n == 1 {
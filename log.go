@@ -0,0 +1,70 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cxr29/log"
+)
+
+// Logger is satisfied by structured loggers such as *slog.Logger, key value pairs
+// follow the message, nil for github.com/cxr29/log. Arguments are logged through
+// Huge.Redact if not nil, Exec and Query take at least Huge.SlowQuery if positive are warned.
+type Logger interface {
+	Debug(string, ...interface{})
+	Warn(string, ...interface{})
+}
+
+func (h Huge) redact(a []interface{}) []interface{} {
+	if h.Redact == nil || len(a) == 0 {
+		return a
+	}
+	b := make([]interface{}, len(a))
+	for i, j := range a {
+		b[i] = h.Redact(j)
+	}
+	return b
+}
+
+func (h Huge) logExpand(s string, a []interface{}, err error) {
+	if h.Logger == nil {
+		log.Debugln(s, h.redact(a))
+		log.ErrDebug(err)
+	} else if err != nil {
+		h.Logger.Debug("huge: expand", "sql", s, "args", h.redact(a), "error", err)
+	} else {
+		h.Logger.Debug("huge: expand", "sql", s, "args", h.redact(a))
+	}
+}
+
+func (h Huge) logSlow(s string, a []interface{}, start time.Time) {
+	if h.SlowQuery <= 0 {
+		return
+	}
+	if d := time.Since(start); d >= h.SlowQuery {
+		if h.Logger == nil {
+			log.ErrWarning(fmt.Errorf("huge: slow query %v: %s %v", d, s, h.redact(a)))
+		} else {
+			h.Logger.Warn("huge: slow query", "sql", s, "args", h.redact(a), "duration", d)
+		}
+	}
+}
+
+func logWarning(l Logger, err error) {
+	if err == nil {
+		return
+	}
+	if l == nil {
+		log.ErrWarning(err)
+	} else {
+		l.Warn("huge: warning", "error", err)
+	}
+}
+
+func (h Huge) logWarning(err error) {
+	logWarning(h.Logger, err)
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/cxr29/huge/query"
+)
+
+var ErrCursor = errors.New("huge: invalid cursor")
+
+type Pager struct {
+	h    Huge
+	q    query.Expression
+	t    *Table
+	a    Columns
+	desc []bool
+	Size int
+}
+
+// Paginate select q of row's table by size ordered by +ASC, -DESC columns,
+// the primary key is appended if not given to make the order total,
+// nullable columns are not keysets so panic.
+func (h Huge) Paginate(q query.Expression, row interface{}, size int, orderBy ...string) *Pager {
+	if q == nil {
+		panic("huge: nil")
+	}
+	if size <= 0 {
+		panic("huge: size")
+	}
+	p := &Pager{h: h, q: q, t: NewTable(row), Size: size}
+	pk := false
+	for _, s := range orderBy {
+		desc := false
+		if len(s) > 0 {
+			switch s[0] {
+			case '+':
+				s = s[1:]
+			case '-':
+				s = s[1:]
+				desc = true
+			}
+		}
+		c := p.t.Find(s)
+		if c == nil || c.isMany() {
+			panic("huge: column not found: " + s)
+		} else if c.isNullable() {
+			panic("huge: nullable order column: " + s)
+		}
+		if c.isPrimaryKey() {
+			pk = true
+		}
+		p.a = append(p.a, c)
+		p.desc = append(p.desc, desc)
+	}
+	if !pk {
+		c := p.t.PrimaryKey()
+		if c == nil {
+			panic(p.t.errNoPrimaryKey())
+		}
+		p.a = append(p.a, c)
+		p.desc = append(p.desc, false)
+	}
+	return p
+}
+
+func (p *Pager) from(s string) query.Expression {
+	return query.X.From(query.E("(?) AS ?", p.q, query.Identifier(s)))
+}
+
+func (p *Pager) orderBy() query.Expression {
+	a := make([]query.Expression, len(p.a))
+	for i, c := range p.a {
+		if p.desc[i] {
+			a[i] = c.Desc()
+		} else {
+			a[i] = c.Asc()
+		}
+	}
+	return query.X.OrderBy(a...)
+}
+
+// Count the rows of all pages.
+func (p *Pager) Count() (n int64, err error) {
+	_, err = p.h.Q(query.SelectCount(), p.from("huge_count")).One(&[...]interface{}{&n})
+	return
+}
+
+// Pages of n rows.
+func (p *Pager) Pages(n int64) int {
+	return int((n + int64(p.Size) - 1) / int64(p.Size))
+}
+
+// Page number from 1 scan into i as All, returns the number of rows of all pages.
+func (p *Pager) Page(number int, i interface{}) (n int64, err error) {
+	if number < 1 {
+		panic("huge: page number")
+	}
+	if n, err = p.Count(); err != nil {
+		return
+	}
+	err = p.h.Q(
+		query.Select(), p.from("huge_page"), p.orderBy(),
+		query.Limit((number-1)*p.Size, p.Size),
+	).All(i)
+	return
+}
+
+// After cursor, empty for the first page, scan into *[]T or *[]*T,
+// returns the cursor of the next page, empty if no more.
+func (p *Pager) After(cursor string, i interface{}) (next string, err error) {
+	v, ok := ptrElem(i)
+	if !ok || v.Kind() != reflect.Slice {
+		panic("huge: not pointer to slice")
+	}
+	where := query.Where()
+	if len(cursor) > 0 {
+		a, err := p.decode(cursor)
+		if err != nil {
+			return "", err
+		}
+		b := make([]query.Operand, len(p.a))
+		for j, c := range p.a {
+			b[j] = c.Operand
+		}
+		where.And(query.Keyset(b, p.desc, a))
+	}
+	if err = p.h.Q(
+		query.Select(), p.from("huge_page"), where, p.orderBy(),
+		query.Limit(p.Size+1),
+	).All(i); err != nil {
+		return
+	}
+	if v.Len() > p.Size {
+		v.SetLen(p.Size)
+		next, err = p.encode(v.Index(p.Size - 1))
+	}
+	return
+}
+
+func (p *Pager) encode(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", p.t.errNil()
+		}
+		v = v.Elem()
+	}
+	a := make([]interface{}, len(p.a))
+	for i, c := range p.a {
+		x, ok := c.field(v)
+		if !ok || !x.CanInterface() {
+			return "", c.errGet()
+		}
+		a[i] = x.Interface()
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (p *Pager) decode(s string) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, ErrCursor
+	}
+	var a []json.RawMessage
+	if err = json.Unmarshal(b, &a); err != nil || len(a) != len(p.a) {
+		return nil, ErrCursor
+	}
+	d := make([]interface{}, len(a))
+	for i, c := range p.a {
+		v := reflect.New(c.last().t)
+		if err = json.Unmarshal(a[i], v.Interface()); err != nil {
+			return nil, ErrCursor
+		}
+		if d[i], err = c.convert(false, true, v.Elem()); err != nil {
+			return nil, err
+		} else if d[i] == nil {
+			return nil, ErrCursor
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// UpdateMap sets the columns of *T by the keys of m, values converted to the field types,
+// then updates only them with version and auto_now, *T is unchanged unless updated.
+func (h Huge) UpdateMap(i interface{}, m map[string]interface{}) (bool, error) {
+	t, v, w := patchRow(i)
+	a := make([]string, 0, len(m))
+	for k, x := range m {
+		c, err := t.patchColumn(k)
+		if err != nil {
+			return false, err
+		} else if err = c.assign(w.Elem(), x); err != nil {
+			return false, err
+		}
+		a = append(a, c.Name)
+	}
+	return h.patch(t, v, w, a)
+}
+
+// ApplyPatch of the RFC 7386 JSON merge patch to *T like UpdateMap,
+// objects are merged into the JSON of the fields, like json columns.
+func (h Huge) ApplyPatch(i interface{}, patch []byte) (bool, error) {
+	t, v, w := patchRow(i)
+	var m map[string]interface{}
+	if err := decodeJSON(patch, &m); err != nil {
+		return false, err
+	} else if m == nil {
+		return false, t.err("patch not an object")
+	}
+	a := make([]string, 0, len(m))
+	for k, x := range m {
+		c, err := t.patchColumn(k)
+		if err != nil {
+			return false, err
+		}
+		if p, ok := x.(map[string]interface{}); ok {
+			f, ok := c.field(w.Elem())
+			if !ok || !f.CanInterface() {
+				return false, c.errGet()
+			}
+			var o interface{}
+			if b, err := json.Marshal(f.Interface()); err != nil {
+				return false, c.err(err.Error())
+			} else if err = decodeJSON(b, &o); err != nil {
+				return false, c.err(err.Error())
+			}
+			x = mergePatch(o, p)
+		}
+		if err = c.assign(w.Elem(), x); err != nil {
+			return false, err
+		}
+		a = append(a, c.Name)
+	}
+	return h.patch(t, v, w, a)
+}
+
+// patchRow of *T returns the table, the row and a copy to patch.
+func patchRow(i interface{}) (*Table, reflect.Value, reflect.Value) {
+	t := NewTable(i)
+	v, p := ptrElem(i)
+	if !p || v.Kind() != reflect.Struct {
+		panic("huge: type unsupported")
+	}
+	if t.PrimaryKey() == nil {
+		panic(t.errNoPrimaryKey())
+	}
+	w := reflect.New(v.Type())
+	w.Elem().Set(v)
+	return t, v, w
+}
+
+func (t *Table) patchColumn(s string) (*Column, error) {
+	c := t.Find(s)
+	if c == nil || c.isMany() {
+		return nil, t.err("column not found: " + s)
+	} else if c.isPrimaryKey() || c.isAutoIncrement() || c.isAutoNow() || c.isAutoNowAdd() || c.isVersion() || c.isGenerated() {
+		return nil, c.err("not updatable")
+	}
+	return c, nil
+}
+
+func (h Huge) patch(t *Table, v, w reflect.Value, a []string) (bool, error) {
+	if len(a) == 0 {
+		return false, t.errNoColumns()
+	}
+	i, err := h.Update(w.Interface(), a...)
+	b, _ := i.(bool)
+	if b {
+		v.Set(w.Elem())
+	}
+	return b, err
+}
+
+// assign i to the field of c in v, by JSON if not assignable, nil sets the zero value.
+func (c *Column) assign(v reflect.Value, i interface{}) error {
+	f, ok := c.field(v)
+	if !ok || !f.CanSet() {
+		return c.errSet()
+	}
+	t := f.Type()
+	if i == nil {
+		f.Set(reflect.Zero(t))
+		return nil
+	}
+	x := reflect.ValueOf(i)
+	if x.Type().AssignableTo(t) {
+		f.Set(x)
+		return nil
+	} else if t.Kind() == reflect.Ptr && x.Type().AssignableTo(t.Elem()) {
+		p := reflect.New(t.Elem())
+		p.Elem().Set(x)
+		f.Set(p)
+		return nil
+	}
+	b, err := json.Marshal(i)
+	if err == nil {
+		p := reflect.New(t)
+		if err = json.Unmarshal(b, p.Interface()); err == nil {
+			f.Set(p.Elem())
+			return nil
+		}
+	}
+	return c.err(err.Error())
+}
+
+// setJSON of i at path in the field of c in v like JSONSet, null as {}.
+func (c *Column) setJSON(v reflect.Value, i interface{}, path []interface{}) error {
+	f, ok := c.field(v)
+	if !ok || !f.CanInterface() {
+		return c.errGet()
+	}
+	var o, x interface{}
+	if b, err := json.Marshal(f.Interface()); err != nil {
+		return c.err(err.Error())
+	} else if err = decodeJSON(b, &o); err != nil {
+		return c.err(err.Error())
+	}
+	var b []byte
+	switch y := i.(type) {
+	case json.RawMessage:
+		b = y
+	case []byte:
+		b = y
+	default:
+		var err error
+		if b, err = json.Marshal(i); err != nil {
+			return c.err(err.Error())
+		}
+	}
+	if err := decodeJSON(b, &x); err != nil {
+		return c.err(err.Error())
+	}
+	if o == nil {
+		o = map[string]interface{}{}
+	}
+	return c.assign(v, setPath(o, x, path))
+}
+
+// setPath of o to x, o is modified, missing keys are added but not their parents,
+// indexes past the end append.
+func setPath(o, x interface{}, path []interface{}) interface{} {
+	if len(path) == 0 {
+		return x
+	}
+	switch k := path[0].(type) {
+	case string:
+		if m, ok := o.(map[string]interface{}); ok {
+			if len(path) == 1 {
+				m[k] = x
+			} else if y, ok := m[k]; ok {
+				m[k] = setPath(y, x, path[1:])
+			}
+			return m
+		}
+	case int:
+		if a, ok := o.([]interface{}); ok && k >= 0 {
+			if k < len(a) {
+				a[k] = setPath(a[k], x, path[1:])
+			} else if len(path) == 1 {
+				a = append(a, x)
+			}
+			return a
+		}
+	}
+	return o
+}
+
+func decodeJSON(b []byte, i interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return d.Decode(i)
+}
+
+// mergePatch p into o by RFC 7386, o is modified.
+func mergePatch(o interface{}, p interface{}) interface{} {
+	m, ok := p.(map[string]interface{})
+	if !ok {
+		return p
+	}
+	n, ok := o.(map[string]interface{})
+	if !ok {
+		n = make(map[string]interface{}, len(m))
+	}
+	for k, x := range m {
+		if x == nil {
+			delete(n, k)
+		} else {
+			n[k] = mergePatch(n[k], x)
+		}
+	}
+	return n
+}
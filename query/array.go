@@ -0,0 +1,198 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var ErrArray = errors.New("query: invalid array")
+
+// Array of bool, integer, float or string slice in PostgreSQL text format,
+// V is a slice to Value and a pointer to slice to Scan, nil slice is NULL.
+type Array struct {
+	V interface{}
+}
+
+var _ driver.Valuer = Array{}
+
+// IsArray reports whether t is a slice of bool, integer, float or string but not bytes.
+func IsArray(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	switch t.Elem().Kind() {
+	case reflect.Bool, reflect.Float32, reflect.Float64, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func (a Array) Value() (driver.Value, error) {
+	v := reflect.ValueOf(a.V)
+	if !v.IsValid() || !IsArray(v.Type()) {
+		return nil, fmt.Errorf("query: array unsupported: %T", a.V)
+	} else if v.IsNil() {
+		return nil, nil
+	}
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, n := 0, v.Len(); i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		switch e := v.Index(i); e.Kind() {
+		case reflect.Bool:
+			if e.Bool() {
+				b.WriteByte('t')
+			} else {
+				b.WriteByte('f')
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			b.WriteString(strconv.FormatInt(e.Int(), 10))
+		case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			b.WriteString(strconv.FormatUint(e.Uint(), 10))
+		case reflect.Float32:
+			b.WriteString(strconv.FormatFloat(e.Float(), 'g', -1, 32))
+		case reflect.Float64:
+			b.WriteString(strconv.FormatFloat(e.Float(), 'g', -1, 64))
+		case reflect.String:
+			b.WriteByte('"')
+			s := e.String()
+			for j := 0; j < len(s); j++ {
+				if s[j] == '"' || s[j] == '\\' {
+					b.WriteByte('\\')
+				}
+				b.WriteByte(s[j])
+			}
+			b.WriteByte('"')
+		}
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+func (a *Array) Scan(src interface{}) error {
+	v := reflect.ValueOf(a.V)
+	if v.Kind() != reflect.Ptr || v.IsNil() || !IsArray(v.Type().Elem()) {
+		return fmt.Errorf("query: array unsupported: %T", a.V)
+	}
+	v = v.Elem()
+	var b []byte
+	switch x := src.(type) {
+	case nil:
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case []byte:
+		b = x
+	case string:
+		b = []byte(x)
+	default:
+		return fmt.Errorf("query: array scan unsupported: %T", src)
+	}
+	c, err := splitArray(b)
+	if err != nil {
+		return err
+	}
+	s := reflect.MakeSlice(v.Type(), len(c), len(c))
+	for i, x := range c {
+		e := s.Index(i)
+		switch e.Kind() {
+		case reflect.Bool:
+			switch x {
+			case "t", "true", "TRUE":
+				e.SetBool(true)
+			case "f", "false", "FALSE":
+			default:
+				return ErrArray
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			j, err := strconv.ParseInt(x, 10, e.Type().Bits())
+			if err != nil {
+				return err
+			}
+			e.SetInt(j)
+		case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			j, err := strconv.ParseUint(x, 10, e.Type().Bits())
+			if err != nil {
+				return err
+			}
+			e.SetUint(j)
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(x, e.Type().Bits())
+			if err != nil {
+				return err
+			}
+			e.SetFloat(f)
+		case reflect.String:
+			e.SetString(x)
+		}
+	}
+	v.Set(s)
+	return nil
+}
+
+// splitArray of one dimension, NULL elements are unsupported.
+func splitArray(b []byte) ([]string, error) {
+	if i := bytes.IndexByte(b, '='); i >= 0 && len(b) > 0 && b[0] == '[' {
+		b = b[i+1:] // [1:3]={...}
+	}
+	if len(b) < 2 || b[0] != '{' || b[len(b)-1] != '}' {
+		return nil, ErrArray
+	}
+	b = b[1 : len(b)-1]
+	a := []string{}
+	if len(b) == 0 {
+		return a, nil
+	}
+	var e []byte
+	for i := 0; ; i++ {
+		e = e[:0]
+		if i < len(b) && b[i] == '"' {
+			for i++; i < len(b) && b[i] != '"'; i++ {
+				if b[i] == '\\' {
+					i++
+				}
+				if i < len(b) {
+					e = append(e, b[i])
+				}
+			}
+			if i >= len(b) {
+				return nil, ErrArray
+			}
+			i++
+		} else {
+			for ; i < len(b) && b[i] != ','; i++ {
+				if b[i] == '{' || b[i] == '"' {
+					return nil, ErrArray
+				}
+				e = append(e, b[i])
+			}
+			if s := string(bytes.TrimSpace(e)); s == "NULL" {
+				return nil, ErrArray
+			}
+		}
+		a = append(a, string(e))
+		if i >= len(b) {
+			return a, nil
+		} else if b[i] != ',' {
+			return nil, ErrArray
+		}
+	}
+}
+
+func array(i interface{}) interface{} {
+	if _, ok := i.(driver.Valuer); !ok && i != nil && IsArray(reflect.TypeOf(i)) {
+		return Array{i}
+	}
+	return i
+}
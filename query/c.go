@@ -211,3 +211,70 @@ func Where(a ...Condition) *Logic {
 func Having(a ...Condition) *Logic {
 	return L1("HAVING ", a...)
 }
+
+type keyset struct {
+	o []Operand
+	d []bool
+	a []interface{}
+}
+
+func (k keyset) Expand(s Starter, i int) (string, []interface{}, error) {
+	n := len(k.o)
+	if n == 0 || n != len(k.d) || n != len(k.a) {
+		return "", nil, nonef("malformed keyset: %v", k)
+	}
+	same := true
+	for j := 0; j < n; j++ {
+		if k.a[j] == nil {
+			return "", nil, nonef("null keyset: %v", k)
+		} else if k.d[j] != k.d[0] {
+			same = false
+		}
+	}
+	if same && n > 1 {
+		switch s.Dialect() {
+		case "postgres", "sqlite3":
+			var b bytes.Buffer
+			b.WriteByte('(')
+			for j := 0; j < n; j++ {
+				if j > 0 {
+					b.WriteString(", ")
+				}
+				b.WriteByte('?')
+			}
+			b.WriteByte(')')
+			x := b.String()
+			if k.d[0] {
+				x += " < " + x
+			} else {
+				x += " > " + x
+			}
+			a := make([]interface{}, 2*n)
+			for j := 0; j < n; j++ {
+				a[j] = k.o[j]
+			}
+			copy(a[n:], k.a)
+			return Expand(E(x, a...), false, s, i)
+		}
+	}
+	a := make([]Condition, n)
+	for j := 0; j < n; j++ {
+		b := make([]Condition, j+1)
+		for l := 0; l < j; l++ {
+			b[l] = k.o[l].Eq(k.a[l])
+		}
+		if k.d[j] {
+			b[j] = k.o[j].Lt(k.a[j])
+		} else {
+			b[j] = k.o[j].Gt(k.a[j])
+		}
+		a[j] = And(b...)
+	}
+	return Expand(Or(a...), false, s, i)
+}
+
+// Keyset rows after values in the order of operands, desc reports descending,
+// row value comparison if the dialect supports and all in one direction.
+func Keyset(operands []Operand, desc []bool, values []interface{}) Condition {
+	return condition{false, keyset{operands, desc, values}}
+}
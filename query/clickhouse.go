@@ -0,0 +1,255 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ClickHouse creates MergeTree tables ordered by the primary key if OrderBy is empty,
+// nullable columns are Nullable(T), columns in LowCardinality are LowCardinality(String).
+type ClickHouse struct {
+	Engine         string
+	PartitionBy    string
+	OrderBy        string
+	Precision      int
+	LowCardinality []string
+}
+
+var (
+	ClickHouseStarter          = ClickHouse{Engine: "MergeTree()", Precision: 3}
+	_                 Starter  = ClickHouseStarter
+	_                 Creater  = ClickHouseStarter
+	_                 Featurer = ClickHouseStarter
+)
+
+func (clickhouse ClickHouse) CreateTable(tableName string, columns []string, temporary, ifNotExists bool) string {
+	var pk []string
+	a := make([]string, len(columns))
+	for i, c := range columns {
+		f := strings.Fields(c)
+		notNull, b := false, f[:2:2]
+		for j := 2; j < len(f); j++ {
+			switch {
+			case f[j] == "PRIMARY" && j+1 < len(f) && f[j+1] == "KEY":
+				pk = append(pk, f[0])
+				notNull = true
+				j++
+			case f[j] == "NOT" && j+1 < len(f) && f[j+1] == "NULL":
+				notNull = true
+				j++
+			case f[j] == "UNIQUE":
+			default:
+				b = append(b, f[j])
+			}
+		}
+		if !notNull {
+			if s := strings.TrimPrefix(b[1], "LowCardinality("); len(s) < len(b[1]) {
+				b[1] = "LowCardinality(Nullable(" + s + ")"
+			} else {
+				b[1] = "Nullable(" + b[1] + ")"
+			}
+		}
+		a[i] = strings.Join(b, " ")
+	}
+	b := CreateTableBuffer(tableName, a, temporary, ifNotExists)
+	if temporary {
+		b.WriteString(" ENGINE = Memory;\n")
+		return b.String()
+	}
+	b.WriteString(" ENGINE = ")
+	if len(clickhouse.Engine) > 0 {
+		b.WriteString(clickhouse.Engine)
+	} else {
+		b.WriteString("MergeTree()")
+	}
+	if len(clickhouse.PartitionBy) > 0 {
+		b.WriteString(" PARTITION BY ")
+		b.WriteString(clickhouse.PartitionBy)
+	}
+	b.WriteString(" ORDER BY ")
+	if len(clickhouse.OrderBy) > 0 {
+		b.WriteString(clickhouse.OrderBy)
+	} else if len(pk) > 0 {
+		writeTuple(b, pk)
+	} else {
+		b.WriteString("tuple()")
+	}
+	b.WriteString(";\n")
+	return b.String()
+}
+
+func writeTuple(b *bytes.Buffer, a []string) {
+	b.WriteByte('(')
+	b.WriteString(strings.Join(a, ", "))
+	b.WriteByte(')')
+}
+
+func (ClickHouse) Dialect() string {
+	return "clickhouse"
+}
+
+// Features lacks auto increment, transactions, in place update and delete and rows affected.
+func (ClickHouse) Features() int {
+	return 0
+}
+
+func (ClickHouse) Parameter(n bool, i int) string {
+	if n {
+		return ""
+	} else {
+		return "?"
+	}
+}
+
+func (ClickHouse) Quote(s string) string {
+	if len(s) == 0 || len(s) > maxLen {
+		return ""
+	}
+	q := false
+	for _, r := range s {
+		switch {
+		case notAllow(r) || r == '`' || r == '\\' || r == ' ':
+			return ""
+		case isDigit(r) || isLetter(r) || r == '_':
+		default:
+			q = true
+		}
+	}
+	if !q {
+		r, _ := utf8.DecodeRuneInString(s)
+		q = isDigit(r)
+	}
+	if !q {
+		q = IsKeyword(ClickHouseKeywords, s)
+	}
+	if q {
+		return "`" + s + "`"
+	} else {
+		return s
+	}
+}
+
+func (clickhouse ClickHouse) Quoted(s string) string {
+	if s[0] == '\'' {
+		return s
+	} else {
+		return clickhouse.Quote(s[1 : len(s)-1])
+	}
+}
+
+func (ClickHouse) Returning(byte, string) string {
+	return ""
+}
+
+func (clickhouse ClickHouse) Mapping(name, goType string, _, option int) (_ string, optionValue string) {
+	if p, s, ok := DecimalType(goType); ok {
+		return fmt.Sprintf("Decimal(%d,%d)", p, s), ""
+	}
+	switch option {
+	case OptionAutoNow, OptionAutoNowAdd:
+		if goType == "time" {
+			optionValue = fmt.Sprintf("DEFAULT now64(%d)", clickhouse.Precision)
+		} else {
+			optionValue = "DEFAULT 0"
+		}
+	case OptionVersion:
+		optionValue = "DEFAULT 1"
+	}
+	switch goType {
+	case "bool":
+		return "Bool", ""
+	case "int8":
+		return "Int8", optionValue
+	case "int16":
+		return "Int16", optionValue
+	case "int32":
+		return "Int32", optionValue
+	case "int", "int64":
+		return "Int64", optionValue
+	case "uint8":
+		return "UInt8", optionValue
+	case "uint16":
+		return "UInt16", optionValue
+	case "uint32":
+		return "UInt32", optionValue
+	case "uint", "uint64":
+		return "UInt64", optionValue
+	case "float32":
+		return "Float32", ""
+	case "float64":
+		return "Float64", ""
+	case "time":
+		return fmt.Sprintf("DateTime64(%d)", clickhouse.Precision), optionValue
+	case "date":
+		if option == OptionAutoNow || option == OptionAutoNowAdd {
+			optionValue = "DEFAULT today()"
+		}
+		return "Date32", optionValue
+	case "time_of_day":
+		return "String", "" // no time type
+	case "string":
+		for _, s := range clickhouse.LowCardinality {
+			if s == name {
+				return "LowCardinality(String)", ""
+			}
+		}
+	}
+	return "String", ""
+}
+
+var ClickHouseKeywords = strings.Split(strings.ToUpper(`ALL
+AND
+ANY
+ARRAY
+AS
+ASC
+BETWEEN
+BY
+CASE
+CAST
+CROSS
+DESC
+DISTINCT
+ELSE
+END
+FINAL
+FORMAT
+FROM
+FULL
+GLOBAL
+GROUP
+HAVING
+IN
+INNER
+INTERVAL
+INTO
+IS
+JOIN
+LEFT
+LIKE
+LIMIT
+NOT
+NULL
+OFFSET
+ON
+OR
+ORDER
+OUTER
+PREWHERE
+RIGHT
+SAMPLE
+SELECT
+SETTINGS
+THEN
+TOTALS
+UNION
+USING
+WHEN
+WHERE
+WITH`), "\n")
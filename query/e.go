@@ -53,12 +53,6 @@ func nonef(format string, a ...interface{}) none {
 	return none(fmt.Sprintf(format, a...))
 }
 
-type empty string
-
-func (e empty) Expand(Starter, int) (string, []interface{}, error) {
-	return "", nil, nil
-}
-
 type value struct {
 	i interface{}
 }
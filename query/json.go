@@ -0,0 +1,200 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// JSON value at path of string keys and int indexes,
+// -> on PostgreSQL, JSON_EXTRACT on MySQL and json_extract on SQLite.
+func (o Operand) JSON(path ...interface{}) Operand {
+	return Operand{jsonPath{o, false, path}}
+}
+
+// JSONText unquoted value at path, ->> on PostgreSQL.
+func (o Operand) JSONText(path ...interface{}) Operand {
+	return Operand{jsonPath{o, true, path}}
+}
+
+// JSONContains i marshaled, @> on PostgreSQL and JSON_CONTAINS on MySQL.
+func (o Operand) JSONContains(i interface{}) Condition {
+	return condition{false, jsonContains{o, i}}
+}
+
+// JSONSet i marshaled at path for Set, updates one key in place, NULL as {}.
+func (o Operand) JSONSet(i interface{}, path ...interface{}) Expression {
+	return jsonSet{o, i, path}
+}
+
+type jsonPath struct {
+	o    Operand
+	text bool
+	p    []interface{}
+}
+
+func (j jsonPath) Expand(s Starter, i int) (string, []interface{}, error) {
+	if len(j.p) == 0 {
+		return "", nil, nonef("empty json path: %v", j)
+	}
+	switch s.Dialect() {
+	case "postgres":
+		var b bytes.Buffer
+		a := make([]interface{}, 1, 1+len(j.p))
+		a[0] = j.o
+		b.WriteByte('?')
+		for k, x := range j.p {
+			if j.text && k == len(j.p)-1 {
+				b.WriteString(" ->> ?")
+			} else {
+				b.WriteString(" -> ?")
+			}
+			switch x := x.(type) {
+			case string:
+				a = append(a, Literal(Quote(x, '\'')))
+			case int:
+				if x < 0 {
+					return "", nil, nonef("json path index: %v", j)
+				}
+				a = append(a, Literal(strconv.Itoa(x)))
+			default:
+				return "", nil, nonef("json path: %v", j)
+			}
+		}
+		return Expand(E(b.String(), a...), false, s, i)
+	case "mysql":
+		p, err := jsonPathString(j.p)
+		if err != nil {
+			return "", nil, err
+		}
+		if j.text {
+			return Expand(E("JSON_UNQUOTE(JSON_EXTRACT(?, ?))", j.o, p), false, s, i)
+		}
+		return Expand(E("JSON_EXTRACT(?, ?)", j.o, p), false, s, i)
+	case "sqlite3":
+		p, err := jsonPathString(j.p)
+		if err != nil {
+			return "", nil, err
+		}
+		return Expand(E("json_extract(?, ?)", j.o, p), false, s, i)
+	}
+	return "", nil, nonef("json unsupported: %s", s.Dialect())
+}
+
+// jsonPathString like '$."a"[0]' of MySQL and SQLite.
+func jsonPathString(p []interface{}) (Literal, error) {
+	b := []byte{'$'}
+	for _, x := range p {
+		switch x := x.(type) {
+		case string:
+			if strings.ContainsAny(x, `"\`) {
+				return "", nonef("json path key: %s", x)
+			}
+			b = append(b, '.', '"')
+			b = append(b, x...)
+			b = append(b, '"')
+		case int:
+			if x < 0 {
+				return "", nonef("json path index: %d", x)
+			}
+			b = append(b, '[')
+			b = strconv.AppendInt(b, int64(x), 10)
+			b = append(b, ']')
+		default:
+			return "", nonef("json path: %v", p)
+		}
+	}
+	return Literal(Quote(string(b), '\'')), nil
+}
+
+// jsonPathArray like '{a,0}' of PostgreSQL.
+func jsonPathArray(p []interface{}) (Literal, error) {
+	a := make([]string, len(p))
+	for k, x := range p {
+		switch x := x.(type) {
+		case string:
+			a[k] = x
+		case int:
+			if x < 0 {
+				return "", nonef("json path index: %d", x)
+			}
+			a[k] = strconv.Itoa(x)
+		default:
+			return "", nonef("json path: %v", p)
+		}
+	}
+	v, err := Array{a}.Value()
+	if err != nil {
+		return "", err
+	}
+	return Literal(Quote(v.(string), '\'')), nil
+}
+
+func jsonMarshal(i interface{}) (string, error) {
+	switch x := i.(type) {
+	case json.RawMessage:
+		return string(x), nil
+	case []byte:
+		return string(x), nil
+	}
+	b, err := json.Marshal(i)
+	return string(b), err
+}
+
+type jsonContains struct {
+	o Operand
+	i interface{}
+}
+
+func (j jsonContains) Expand(s Starter, i int) (string, []interface{}, error) {
+	v, err := jsonMarshal(j.i)
+	if err != nil {
+		return "", nil, err
+	}
+	switch s.Dialect() {
+	case "postgres":
+		return Expand(E("? @> CAST(? AS JSONB)", j.o, v), false, s, i)
+	case "mysql":
+		return Expand(E("JSON_CONTAINS(?, ?)", j.o, v), false, s, i)
+	}
+	return "", nil, nonef("json contains unsupported: %s", s.Dialect())
+}
+
+type jsonSet struct {
+	o Operand
+	i interface{}
+	p []interface{}
+}
+
+func (j jsonSet) Expand(s Starter, i int) (string, []interface{}, error) {
+	if len(j.p) == 0 {
+		return "", nil, nonef("empty json path: %v", j)
+	}
+	v, err := jsonMarshal(j.i)
+	if err != nil {
+		return "", nil, err
+	}
+	var p Literal
+	switch s.Dialect() {
+	case "postgres":
+		if p, err = jsonPathArray(j.p); err == nil {
+			return Expand(E("jsonb_set(COALESCE(?, '{}'), ?, CAST(? AS JSONB))", j.o, p, v), false, s, i)
+		}
+	case "mysql":
+		if p, err = jsonPathString(j.p); err == nil {
+			return Expand(E("JSON_SET(COALESCE(?, '{}'), ?, CAST(? AS JSON))", j.o, p, v), false, s, i)
+		}
+	case "sqlite3":
+		if p, err = jsonPathString(j.p); err == nil {
+			return Expand(E("json_set(COALESCE(?, '{}'), ?, json(?))", j.o, p, v), false, s, i)
+		}
+	default:
+		err = nonef("json set unsupported: %s", s.Dialect())
+	}
+	return "", nil, err
+}
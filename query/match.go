@@ -0,0 +1,150 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"bytes"
+	"strings"
+)
+
+const (
+	MatchNatural = iota
+	MatchBoolean
+	MatchPhrase
+)
+
+// Match columns against text in mode, MATCH AGAINST on MySQL, to_tsvector @@ tsquery on PostgreSQL,
+// on SQLite columns of a FTS5 table, qualified by the table if more than one.
+func Match(columns []Operand, text string, mode int) Condition {
+	return condition{false, match{false, columns, text, mode}}
+}
+
+// Relevance of Match for ordering, the higher the more relevant.
+func Relevance(columns []Operand, text string, mode int) Expression {
+	return match{true, columns, text, mode}
+}
+
+// Document of columns to index for Match on PostgreSQL.
+func Document(columns []Operand) Expression {
+	return document(columns)
+}
+
+type document []Operand
+
+func (d document) Expand(s Starter, i int) (string, []interface{}, error) {
+	if len(d) == 0 {
+		return "", nil, nonef("empty document")
+	} else if s.Dialect() != "postgres" {
+		return "", nil, nonef("document unsupported: %s", s.Dialect())
+	}
+	var b bytes.Buffer
+	a := make([]interface{}, len(d))
+	b.WriteString("to_tsvector(")
+	b.WriteString(Quote(textSearch(s), '\''))
+	b.WriteString(", ")
+	for k, o := range d {
+		if k > 0 {
+			b.WriteString(" || ' ' || ")
+		}
+		b.WriteString("COALESCE(?, '')")
+		a[k] = o
+	}
+	b.WriteByte(')')
+	return Expand(E(b.String(), a...), false, s, i)
+}
+
+func textSearch(s Starter) string {
+	if p, ok := s.(PostgreSQL); ok && len(p.TextSearch) > 0 {
+		return p.TextSearch
+	}
+	return "simple"
+}
+
+type match struct {
+	r bool
+	o []Operand
+	s string
+	m int
+}
+
+func (m match) Expand(s Starter, i int) (string, []interface{}, error) {
+	if len(m.o) == 0 {
+		return "", nil, nonef("empty match: %v", m)
+	} else if m.m < MatchNatural || m.m > MatchPhrase {
+		return "", nil, nonef("match mode: %v", m)
+	}
+	switch s.Dialect() {
+	case "mysql":
+		var b bytes.Buffer
+		a := make([]interface{}, len(m.o)+1)
+		b.WriteString("MATCH (")
+		for k, o := range m.o {
+			if k > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('?')
+			a[k] = o
+		}
+		text := m.s
+		switch m.m {
+		case MatchNatural:
+			b.WriteString(") AGAINST (? IN NATURAL LANGUAGE MODE)")
+		case MatchBoolean:
+			b.WriteString(") AGAINST (? IN BOOLEAN MODE)")
+		case MatchPhrase:
+			b.WriteString(") AGAINST (? IN BOOLEAN MODE)")
+			text = `"` + strings.Replace(text, `"`, " ", -1) + `"`
+		}
+		a[len(m.o)] = text
+		return Expand(E(b.String(), a...), false, s, i)
+	case "postgres":
+		q := "plainto_tsquery"
+		switch m.m {
+		case MatchBoolean:
+			q = "websearch_to_tsquery"
+		case MatchPhrase:
+			q = "phraseto_tsquery"
+		}
+		q += "(" + Quote(textSearch(s), '\'') + ", ?)"
+		if m.r {
+			return Expand(E("ts_rank(?, "+q+")", document(m.o), m.s), false, s, i)
+		}
+		return Expand(E("? @@ "+q, document(m.o), m.s), false, s, i)
+	case "sqlite3":
+		var text string
+		switch m.m {
+		case MatchNatural:
+			a := strings.Fields(m.s)
+			for k, v := range a {
+				a[k] = Quote(v, '"')
+			}
+			text = strings.Join(a, " ")
+		case MatchBoolean:
+			text = m.s
+		case MatchPhrase:
+			text = Quote(m.s, '"')
+		}
+		if len(m.o) == 1 {
+			if m.r {
+				return "-rank", nil, nil
+			}
+			return Expand(E("? MATCH ?", m.o[0], text), false, s, i)
+		}
+		var t Qualifier
+		c := make([]string, len(m.o))
+		for k, o := range m.o {
+			q, ok := o.e.(Qualifier)
+			if !ok || len(q) < 2 || (t != nil && strings.Join(q[:len(q)-1], ".") != strings.Join(t, ".")) {
+				return "", nil, nonef("match columns of one FTS5 table: %v", m)
+			}
+			t, c[k] = q[:len(q)-1], q[len(q)-1]
+		}
+		if m.r {
+			return Expand(E("-bm25(?)", t), false, s, i)
+		}
+		return Expand(E("? MATCH ?", t, "{"+strings.Join(c, " ")+"} : ("+text+")"), false, s, i)
+	}
+	return "", nil, nonef("match unsupported: %s", s.Dialect())
+}
@@ -6,22 +6,26 @@ package query
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 	"unicode"
 	"unicode/utf8"
 )
 
+// MySQL of the server Version, RETURNING of insert and delete since MariaDB 10.5.
 type MySQL struct {
 	Engine   string
 	Charset  string
 	Collate  string
 	ZeroTime string
+	Version  string
 }
 
 var (
-	MySQLStarter         = MySQL{ZeroTime: time.Unix(0, 0).Local().Format("'2006-01-02 15:04:05'")}
-	_            Starter = MySQLStarter
+	MySQLStarter            = MySQL{ZeroTime: time.Unix(0, 0).Local().Format("'2006-01-02 15:04:05'")}
+	_            Starter    = MySQLStarter
+	_            Classifier = MySQLStarter
 )
 
 func (mysql MySQL) CreateTable(tableName string, columns []string, temporary, ifNotExists bool) string {
@@ -54,10 +58,27 @@ func (MySQL) Parameter(n bool, i int) string {
 	}
 }
 
-func (MySQL) Returning(byte, string) string {
+func (mysql MySQL) Returning(b byte, c string) string {
+	v := strings.TrimPrefix(mysql.Version, "5.5.5-")
+	if b != 'u' && strings.Contains(v, "MariaDB") && VersionAtLeast(v, 10, 5) {
+		return "RETURNING " + c
+	}
 	return ""
 }
 
+// Retryable lock wait timeout 1205 and deadlock 1213.
+func (MySQL) Retryable(err error) bool {
+	if v := ErrorField(err, "Number"); v.IsValid() {
+		switch k := v.Kind(); {
+		case k >= reflect.Uint && k <= reflect.Uint64:
+			return v.Uint() == 1205 || v.Uint() == 1213
+		case k >= reflect.Int && k <= reflect.Int64:
+			return v.Int() == 1205 || v.Int() == 1213
+		}
+	}
+	return SQLState(err) == "40001"
+}
+
 func (MySQL) Quote(s string) string {
 	if len(s) == 0 || len(s) > maxLen {
 		return ""
@@ -120,6 +141,9 @@ var (
 )
 
 func (mysql MySQL) Mapping(_, goType string, maxSize, option int) (string, string) {
+	if p, s, ok := DecimalType(goType); ok {
+		return fmt.Sprintf("DECIMAL(%d,%d)", p, s), "0"
+	}
 	a, ok := mysqlTypes[goType]
 	switch option {
 	case OptionAutoIncrement:
@@ -143,6 +167,14 @@ func (mysql MySQL) Mapping(_, goType string, maxSize, option int) (string, strin
 				a[1] = "DEFAULT CURRENT_TIMESTAMP"
 			}
 			a[0] = "DATETIME"
+		case "date", "time_of_day":
+			a[0], a[1] = "DATE", "'1970-01-01'"
+			if goType == "time_of_day" {
+				a[0], a[1] = "TIME", "'00:00:00'"
+			}
+			if option != OptionZeroValue {
+				a[1] = "" // expression defaults need MySQL 8.0.13
+			}
 		case "bytes", "gob":
 			if maxSize > 0 && maxSize <= 255 {
 				a[0] = fmt.Sprintf("VARBINARY(%d)", maxSize)
@@ -130,6 +130,21 @@ func (o Operand) In(a ...interface{}) Condition {
 	return C(b.String(), d...)
 }
 
+// ArrayContains @> of PostgreSQL arrays, a slice i is passed as Array.
+func (o Operand) ArrayContains(i interface{}) Condition {
+	return C("? @> ?", o, array(i))
+}
+
+// Overlap && of PostgreSQL arrays.
+func (o Operand) Overlap(i interface{}) Condition {
+	return C("? && ?", o, array(i))
+}
+
+// Any = ANY(?) of a PostgreSQL array, a single parameter instead of IN list.
+func (o Operand) Any(i interface{}) Condition {
+	return C("? = ANY(?)", o, array(i))
+}
+
 func (o Operand) Between(i, j interface{}) Condition {
 	return C("? BETWEEN ? AND ?", o, i, j)
 }
@@ -11,11 +11,15 @@ import (
 	"unicode/utf8"
 )
 
-type PostgreSQL struct{}
+// PostgreSQL with TextSearch configuration of Match, simple if empty.
+type PostgreSQL struct {
+	TextSearch string
+}
 
 var (
-	PostgreSQLStarter         = PostgreSQL{}
-	_                 Starter = PostgreSQLStarter
+	PostgreSQLStarter            = PostgreSQL{}
+	_                 Starter    = PostgreSQLStarter
+	_                 Classifier = PostgreSQLStarter
 )
 
 func (PostgreSQL) Dialect() string {
@@ -70,7 +74,26 @@ func (PostgreSQL) Returning(b byte, c string) string {
 	return "RETURNING " + c
 }
 
-func (PostgreSQL) Mapping(_, goType string, maxSize, option int) (_ string, optionValue string) {
+// Retryable serialization failure 40001 and deadlock detected 40P01.
+func (PostgreSQL) Retryable(err error) bool {
+	switch SQLState(err) {
+	case "40001", "40P01":
+		return true
+	}
+	return false
+}
+
+func (postgresql PostgreSQL) Mapping(name, goType string, maxSize, option int) (_ string, optionValue string) {
+	if p, s, ok := DecimalType(goType); ok {
+		return fmt.Sprintf("NUMERIC(%d,%d)", p, s), "0"
+	}
+	if strings.HasPrefix(goType, "[]") {
+		if goType == "[]string" && maxSize == 0 {
+			return "TEXT[]", ""
+		}
+		s, _ := postgresql.Mapping(name, goType[2:], maxSize, OptionZeroValue)
+		return s + "[]", ""
+	}
 	switch option {
 	case OptionAutoIncrement:
 		switch goType {
@@ -113,6 +136,22 @@ func (PostgreSQL) Mapping(_, goType string, maxSize, option int) (_ string, opti
 			optionValue = "'1970-01-01T00:00:00Z'"
 		}
 		return "TIMESTAMP WITH TIME ZONE", optionValue
+	case "date":
+		switch option {
+		case OptionZeroValue:
+			optionValue = "'1970-01-01'"
+		case OptionAutoNow, OptionAutoNowAdd:
+			optionValue = "DEFAULT CURRENT_DATE"
+		}
+		return "DATE", optionValue
+	case "time_of_day":
+		switch option {
+		case OptionZeroValue:
+			optionValue = "'00:00:00'"
+		case OptionAutoNow, OptionAutoNowAdd:
+			optionValue = "DEFAULT LOCALTIME"
+		}
+		return "TIME", optionValue
 	case "bytes", "gob":
 		return "BYTEA", ""
 	case "json":
@@ -6,6 +6,7 @@ package query
 
 import (
 	"bytes"
+	"fmt"
 )
 
 type Query struct {
@@ -210,6 +211,11 @@ func Update(s string) Expression {
 	return E("UPDATE ?", Identifier(s))
 }
 
+// AlterUpdate starts an update by ALTER TABLE, followed by X.Assign.
+func AlterUpdate(s string) Expression {
+	return E("ALTER TABLE ? UPDATE", Identifier(s))
+}
+
 func Set(c string, i interface{}) *QueryS {
 	return X.Set().Add(c, i)
 }
@@ -218,6 +224,10 @@ func Delete(s string) Expression {
 	return E("DELETE FROM ?", Identifier(s))
 }
 
+func AlterDelete(s string) Expression {
+	return E("ALTER TABLE ? DELETE", Identifier(s))
+}
+
 func SelectCount() Expression {
 	return Literal("SELECT COUNT(*)")
 }
@@ -243,37 +253,42 @@ func GroupBy(a ...string) *Query {
 	return Q3Empty("GROUP BY ", ", ", "").Add(a...)
 }
 
+// Limiter is a Starter with its own limit and offset clause, negative for none.
+type Limiter interface {
+	Limit(int, int) string
+}
+
+type limit [2]int
+
+func (e limit) Expand(s Starter, _ int) (string, []interface{}, error) {
+	if l, ok := s.(Limiter); ok {
+		return l.Limit(e[0], e[1]), nil, nil
+	} else if e[0] < 0 && e[1] < 0 {
+		return "", nil, nil
+	} else if e[1] < 0 {
+		return fmt.Sprintf("LIMIT %d", e[0]), nil, nil
+	} else if e[0] < 0 {
+		return fmt.Sprintf("OFFSET %d", e[1]), nil, nil
+	}
+	return fmt.Sprintf("LIMIT %d OFFSET %d", e[0], e[1]), nil, nil
+}
+
+// Limit n, or offset and n.
 func Limit(a ...int) Expression {
 	switch len(a) {
 	case 0:
-		return empty("LIMIT")
+		return limit{-1, -1}
 	case 1:
-		return limit(a[0])
+		return limit{a[0], -1}
 	case 2:
-		if a[0] < 0 {
-			return limit(a[1])
-		} else if a[1] < 0 {
-			return Offset(a[0])
-		} else {
-			return Literalf("LIMIT %d OFFSET %d", a[1], a[0])
-		}
+		return limit{a[1], a[0]}
 	default:
 		return nonef("limit: %v", a)
 	}
 }
 
-func limit(n int) Expression {
-	if n < 0 {
-		return empty("LIMIT")
-	}
-	return Literalf("LIMIT %d", n)
-}
-
 func Offset(n int) Expression {
-	if n < 0 {
-		return empty("OFFSET")
-	}
-	return Literalf("OFFSET %d", n)
+	return limit{-1, n}
 }
 
 type x byte
@@ -288,6 +303,11 @@ func (x) Set(a ...interface{}) *QueryS {
 	return Q3S1("SET ", " = ", ", ", "", a...)
 }
 
+// Assign is Set without the SET keyword.
+func (x) Assign(a ...interface{}) *QueryS {
+	return Q3S1("", " = ", ", ", "", a...)
+}
+
 func (x) SelectDistinct(a ...Expression) *Query {
 	return SelectDistinct().Append(a...)
 }
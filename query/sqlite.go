@@ -6,16 +6,21 @@ package query
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"unicode/utf8"
 )
 
-type SQLite struct{}
+// SQLite of the server Version, RETURNING since 3.35.
+type SQLite struct {
+	Version string
+}
 
 var (
-	SQLiteStarter         = SQLite{}
-	_             Starter = SQLiteStarter
+	SQLiteStarter            = SQLite{}
+	_             Starter    = SQLiteStarter
+	_             Classifier = SQLiteStarter
 )
 
 func (SQLite) Dialect() string {
@@ -66,11 +71,28 @@ func (sqlite SQLite) Quoted(s string) string {
 	}
 }
 
-func (SQLite) Returning(byte, string) string {
+func (sqlite SQLite) Returning(_ byte, c string) string {
+	if VersionAtLeast(sqlite.Version, 3, 35) {
+		return "RETURNING " + c
+	}
 	return ""
 }
 
+// Retryable busy 5 and locked 6.
+func (SQLite) Retryable(err error) bool {
+	if v := ErrorField(err, "Code"); v.IsValid() {
+		switch k := v.Kind(); {
+		case k >= reflect.Int && k <= reflect.Int64:
+			return v.Int() == 5 || v.Int() == 6
+		}
+	}
+	return false
+}
+
 func (SQLite) Mapping(_, goType string, maxSize, option int) (_ string, optionValue string) {
+	if _, _, ok := DecimalType(goType); ok {
+		return "TEXT", "'0'" // exact, NUMERIC affinity would convert to REAL
+	}
 	switch option {
 	case OptionAutoIncrement:
 		optionValue = "AUTOINCREMENT"
@@ -98,6 +120,22 @@ func (SQLite) Mapping(_, goType string, maxSize, option int) (_ string, optionVa
 			optionValue = "'1970-01-01T00:00:00Z'"
 		}
 		return "DATETIME", optionValue
+	case "date":
+		switch option {
+		case OptionZeroValue:
+			optionValue = "'1970-01-01'"
+		case OptionAutoNow, OptionAutoNowAdd:
+			optionValue = "DEFAULT CURRENT_DATE"
+		}
+		return "DATE", optionValue
+	case "time_of_day":
+		switch option {
+		case OptionZeroValue:
+			optionValue = "'00:00:00'"
+		case OptionAutoNow, OptionAutoNowAdd:
+			optionValue = "DEFAULT CURRENT_TIME"
+		}
+		return "TIME", optionValue
 	case "bytes", "gob":
 		return "BLOB", ""
 	case "string": // interface, json, xml
@@ -0,0 +1,413 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Outputer is a Starter whose returning clause goes before VALUES of insert and WHERE of update.
+type Outputer interface {
+	Output(byte, string) string
+}
+
+type SQLServer struct{}
+
+var (
+	SQLServerStarter            = SQLServer{}
+	_                Starter    = SQLServerStarter
+	_                Creater    = SQLServerStarter
+	_                Limiter    = SQLServerStarter
+	_                Outputer   = SQLServerStarter
+	_                Classifier = SQLServerStarter
+)
+
+func (SQLServer) CreateTable(tableName string, columns []string, temporary, ifNotExists bool) string {
+	if temporary {
+		if tableName[0] == '[' {
+			tableName = "[#" + tableName[1:]
+		} else {
+			tableName = "#" + tableName
+		}
+	}
+	b := CreateTableBuffer(tableName, columns, false, false)
+	b.WriteString(";\n")
+	if ifNotExists {
+		if temporary {
+			return fmt.Sprintf("IF OBJECT_ID(N'tempdb..%s') IS NULL\n%s", tableName, b.String())
+		}
+		return fmt.Sprintf("IF OBJECT_ID(N'%s', N'U') IS NULL\n%s", tableName, b.String())
+	}
+	return b.String()
+}
+
+func (SQLServer) Dialect() string {
+	return "sqlserver"
+}
+
+func (SQLServer) Parameter(n bool, i int) string {
+	if n {
+		return "@p" + strconv.Itoa(i)
+	} else {
+		return ""
+	}
+}
+
+func (SQLServer) Quote(s string) string {
+	if len(s) == 0 || len(s) > maxLen {
+		return ""
+	}
+	q := false
+	for _, r := range s {
+		switch {
+		case notAllow(r) || r == '[' || r == ']':
+			return ""
+		case isDigit(r) || isLetter(r) || r == '_' || r == '@' || r == '#' || r == '$':
+		default:
+			q = true
+		}
+	}
+	if !q {
+		r, _ := utf8.DecodeRuneInString(s)
+		q = !isLetter(r) && r != '_'
+	}
+	if !q {
+		q = IsKeyword(SQLServerKeywords, s)
+	}
+	if q {
+		return "[" + s + "]"
+	} else {
+		return s
+	}
+}
+
+func (sqlserver SQLServer) Quoted(s string) string {
+	if s[0] == '\'' {
+		return s
+	} else {
+		return sqlserver.Quote(s[1 : len(s)-1])
+	}
+}
+
+func (SQLServer) Returning(byte, string) string {
+	return ""
+}
+
+// Output columns c separated by comma, DELETED of delete otherwise INSERTED.
+func (SQLServer) Output(b byte, c string) string {
+	p := "INSERTED."
+	if b == 'd' {
+		p = "DELETED."
+	}
+	return "OUTPUT " + p + strings.Replace(c, ", ", ", "+p, -1)
+}
+
+// Limit by OFFSET FETCH, which needs ORDER BY.
+func (SQLServer) Limit(n, offset int) string {
+	if n < 0 && offset < 0 {
+		return ""
+	} else if offset < 0 {
+		offset = 0
+	}
+	if n < 0 {
+		return fmt.Sprintf("OFFSET %d ROWS", offset)
+	}
+	return fmt.Sprintf("OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, n)
+}
+
+// Retryable deadlock victim 1205.
+func (SQLServer) Retryable(err error) bool {
+	if v := ErrorField(err, "Number"); v.IsValid() {
+		switch k := v.Kind(); {
+		case k >= reflect.Int && k <= reflect.Int64:
+			return v.Int() == 1205
+		case k >= reflect.Uint && k <= reflect.Uint64:
+			return v.Uint() == 1205
+		}
+	}
+	return SQLState(err) == "40001"
+}
+
+func (SQLServer) Mapping(_, goType string, maxSize, option int) (_ string, optionValue string) {
+	if p, s, ok := DecimalType(goType); ok {
+		return fmt.Sprintf("DECIMAL(%d,%d)", p, s), "0"
+	}
+	switch option {
+	case OptionAutoIncrement:
+		switch goType {
+		case "int64", "uint64", "uint32", "uint":
+			return "BIGINT IDENTITY(1,1)", ""
+		default:
+			return "INT IDENTITY(1,1)", ""
+		}
+	case OptionAutoNow, OptionAutoNowAdd:
+		if goType == "time" {
+			optionValue = "DEFAULT SYSDATETIME()"
+		} else {
+			optionValue = "DEFAULT 0"
+		}
+	case OptionVersion:
+		optionValue = "DEFAULT 1"
+	}
+	switch goType {
+	case "bool":
+		return "BIT", "0"
+	case "uint8":
+		if option == OptionZeroValue {
+			optionValue = "0"
+		}
+		return "TINYINT", optionValue
+	case "int8", "int16":
+		if option == OptionZeroValue {
+			optionValue = "0"
+		}
+		return "SMALLINT", optionValue
+	case "int", "int32", "uint16":
+		if option == OptionZeroValue {
+			optionValue = "0"
+		}
+		return "INT", optionValue
+	case "int64", "uint", "uint32", "uint64":
+		if option == OptionZeroValue {
+			optionValue = "0"
+		}
+		return "BIGINT", optionValue
+	case "float32":
+		return "REAL", "0"
+	case "float64":
+		return "FLOAT", "0"
+	case "time":
+		if option == OptionZeroValue {
+			optionValue = "'1970-01-01T00:00:00'"
+		}
+		return "DATETIME2", optionValue
+	case "date":
+		switch option {
+		case OptionZeroValue:
+			optionValue = "'1970-01-01'"
+		case OptionAutoNow, OptionAutoNowAdd:
+			optionValue = "DEFAULT CAST(SYSDATETIME() AS DATE)"
+		}
+		return "DATE", optionValue
+	case "time_of_day":
+		switch option {
+		case OptionZeroValue:
+			optionValue = "'00:00:00'"
+		case OptionAutoNow, OptionAutoNowAdd:
+			optionValue = "DEFAULT CAST(SYSDATETIME() AS TIME)"
+		}
+		return "TIME", optionValue
+	case "bytes", "gob":
+		if maxSize > 0 && maxSize <= 8000 {
+			return fmt.Sprintf("VARBINARY(%d)", maxSize), ""
+		} else {
+			return "VARBINARY(MAX)", ""
+		}
+	case "xml":
+		return "XML", ""
+	case "json":
+		return "NVARCHAR(MAX)", ""
+	case "string": // interface
+		optionValue = "''"
+		fallthrough
+	default:
+		if maxSize == 0 {
+			return "NVARCHAR(255)", optionValue
+		} else if maxSize > 0 && maxSize <= 4000 {
+			return fmt.Sprintf("NVARCHAR(%d)", maxSize), optionValue
+		} else {
+			return "NVARCHAR(MAX)", optionValue
+		}
+	}
+}
+
+var SQLServerKeywords = strings.Split(strings.ToUpper(`ADD
+ALL
+ALTER
+AND
+ANY
+AS
+ASC
+AUTHORIZATION
+BACKUP
+BEGIN
+BETWEEN
+BREAK
+BROWSE
+BULK
+BY
+CASCADE
+CASE
+CHECK
+CHECKPOINT
+CLOSE
+CLUSTERED
+COALESCE
+COLLATE
+COLUMN
+COMMIT
+COMPUTE
+CONSTRAINT
+CONTAINS
+CONTAINSTABLE
+CONTINUE
+CONVERT
+CREATE
+CROSS
+CURRENT
+CURRENT_DATE
+CURRENT_TIME
+CURRENT_TIMESTAMP
+CURRENT_USER
+CURSOR
+DATABASE
+DBCC
+DEALLOCATE
+DECLARE
+DEFAULT
+DELETE
+DENY
+DESC
+DISK
+DISTINCT
+DISTRIBUTED
+DOUBLE
+DROP
+DUMP
+ELSE
+END
+ERRLVL
+ESCAPE
+EXCEPT
+EXEC
+EXECUTE
+EXISTS
+EXIT
+EXTERNAL
+FETCH
+FILE
+FILLFACTOR
+FOR
+FOREIGN
+FREETEXT
+FREETEXTTABLE
+FROM
+FULL
+FUNCTION
+GOTO
+GRANT
+GROUP
+HAVING
+HOLDLOCK
+IDENTITY
+IDENTITY_INSERT
+IDENTITYCOL
+IF
+IN
+INDEX
+INNER
+INSERT
+INTERSECT
+INTO
+IS
+JOIN
+KEY
+KILL
+LEFT
+LIKE
+LINENO
+LOAD
+MERGE
+NATIONAL
+NOCHECK
+NONCLUSTERED
+NOT
+NULL
+NULLIF
+OF
+OFF
+OFFSETS
+ON
+OPEN
+OPENDATASOURCE
+OPENQUERY
+OPENROWSET
+OPENXML
+OPTION
+OR
+ORDER
+OUTER
+OVER
+PERCENT
+PIVOT
+PLAN
+PRECISION
+PRIMARY
+PRINT
+PROC
+PROCEDURE
+PUBLIC
+RAISERROR
+READ
+READTEXT
+RECONFIGURE
+REFERENCES
+REPLICATION
+RESTORE
+RESTRICT
+RETURN
+REVERT
+REVOKE
+RIGHT
+ROLLBACK
+ROWCOUNT
+ROWGUIDCOL
+RULE
+SAVE
+SCHEMA
+SECURITYAUDIT
+SELECT
+SEMANTICKEYPHRASETABLE
+SEMANTICSIMILARITYDETAILSTABLE
+SEMANTICSIMILARITYTABLE
+SESSION_USER
+SET
+SETUSER
+SHUTDOWN
+SOME
+STATISTICS
+SYSTEM_USER
+TABLE
+TABLESAMPLE
+TEXTSIZE
+THEN
+TO
+TOP
+TRAN
+TRANSACTION
+TRIGGER
+TRUNCATE
+TRY_CONVERT
+TSEQUAL
+UNION
+UNIQUE
+UNPIVOT
+UPDATE
+UPDATETEXT
+USE
+USER
+VALUES
+VARYING
+VIEW
+WAITFOR
+WHEN
+WHERE
+WHILE
+WITH
+WRITETEXT`), "\n")
@@ -25,6 +25,42 @@ type Starter interface {
 	Mapping(string, string, int, int) (string, string)
 }
 
+// Classifier reports whether an error is retryable, such as deadlock or serialization failure.
+type Classifier interface {
+	Retryable(error) bool
+}
+
+const (
+	FeatureAutoIncrement = 1 << iota
+	FeatureTransaction
+	FeatureUpdate
+	FeatureRowsAffected
+)
+
+// Featurer is a Starter lacks some features, all are supported if not implemented,
+// without FeatureUpdate update and delete by ALTER TABLE.
+type Featurer interface {
+	Features() int
+}
+
+func Supports(s Starter, feature int) bool {
+	if f, ok := s.(Featurer); ok {
+		return f.Features()&feature == feature
+	}
+	return true
+}
+
+// DecimalType of goType decimal:precision:scale, or decimal for 18 and 4.
+func DecimalType(goType string) (precision, scale int, ok bool) {
+	if goType == "decimal" {
+		return 18, 4, true
+	}
+	if _, err := fmt.Sscanf(goType, "decimal:%d:%d", &precision, &scale); err == nil {
+		return precision, scale, true
+	}
+	return 0, 0, false
+}
+
 func NewStarter(dialect string) Starter {
 	switch dialect {
 	case "mysql":
@@ -33,6 +69,10 @@ func NewStarter(dialect string) Starter {
 		return PostgreSQLStarter
 	case "sqlite3":
 		return SQLiteStarter
+	case "sqlserver":
+		return SQLServerStarter
+	case "clickhouse":
+		return ClickHouseStarter
 	default:
 		return StandardStarter
 	}
@@ -41,8 +81,9 @@ func NewStarter(dialect string) Starter {
 type Standard struct{}
 
 var (
-	StandardStarter         = Standard{}
-	_               Starter = StandardStarter
+	StandardStarter            = Standard{}
+	_               Starter    = StandardStarter
+	_               Classifier = StandardStarter
 )
 
 func (Standard) Dialect() string {
@@ -81,7 +122,14 @@ func (Standard) Returning(byte, string) string {
 	return ""
 }
 
+func (Standard) Retryable(err error) bool {
+	return SQLState(err) == "40001"
+}
+
 func (Standard) Mapping(_, goType string, maxSize, option int) (_, optionValue string) {
+	if p, s, ok := DecimalType(goType); ok {
+		return fmt.Sprintf("DECIMAL(%d,%d)", p, s), "0"
+	}
 	switch option {
 	case OptionAutoIncrement:
 		optionValue = "GENERATED BY DEFAULT AS IDENTITY"
@@ -116,6 +164,22 @@ func (Standard) Mapping(_, goType string, maxSize, option int) (_, optionValue s
 			optionValue = "'1970-01-01T00:00:00Z'"
 		}
 		return "TIMESTAMP WITH TIME ZONE", optionValue
+	case "date":
+		switch option {
+		case OptionZeroValue:
+			optionValue = "'1970-01-01'"
+		case OptionAutoNow, OptionAutoNowAdd:
+			optionValue = "DEFAULT CURRENT_DATE"
+		}
+		return "DATE", optionValue
+	case "time_of_day":
+		switch option {
+		case OptionZeroValue:
+			optionValue = "'00:00:00'"
+		case OptionAutoNow, OptionAutoNowAdd:
+			optionValue = "DEFAULT LOCALTIME"
+		}
+		return "TIME", optionValue
 	case "bytes", "gob":
 		if maxSize > 0 && maxSize <= 255 {
 			return fmt.Sprintf("BINARY LARGE OBJECT(%d)", maxSize), ""
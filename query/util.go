@@ -5,7 +5,10 @@
 package query
 
 import (
+	"errors"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -116,6 +119,32 @@ func init() {
 	sort.Strings(MySQLKeywords)
 	sort.Strings(PostgreSQLKeywords)
 	sort.Strings(SQLiteKeywords)
+	sort.Strings(SQLServerKeywords)
+	sort.Strings(ClickHouseKeywords)
+}
+
+// VersionAtLeast reports whether the leading numbers of version v are at least a.
+func VersionAtLeast(v string, a ...int) bool {
+	for _, i := range a {
+		j := 0
+		for j < len(v) && isDigit(rune(v[j])) {
+			j++
+		}
+		if j == 0 {
+			return false
+		}
+		n, err := strconv.Atoi(v[:j])
+		if err != nil {
+			return false
+		} else if n != i {
+			return n > i
+		}
+		v = v[j:]
+		if len(v) > 0 && v[0] == '.' {
+			v = v[1:]
+		}
+	}
+	return true
 }
 
 func IsKeyword(a []string, s string) bool {
@@ -123,3 +152,37 @@ func IsKeyword(a []string, s string) bool {
 	i := sort.SearchStrings(a, s)
 	return i >= 0 && i < len(a) && a[i] == s
 }
+
+// ErrorField of the name in the first struct of the error chain has it, without importing the driver.
+func ErrorField(err error, name string) reflect.Value {
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct {
+			if f := v.FieldByName(name); f.IsValid() {
+				return f
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// SQLState of the error by method SQLState or string field Code.
+func SQLState(err error) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if s, ok := e.(interface {
+			SQLState() string
+		}); ok {
+			return s.SQLState()
+		}
+	}
+	if v := ErrorField(err, "Code"); v.IsValid() && v.Kind() == reflect.String {
+		return v.String()
+	}
+	return ""
+}
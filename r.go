@@ -5,15 +5,14 @@
 package huge
 
 import (
-	"database/sql"
 	"reflect"
 
 	"github.com/cxr29/huge/query"
-	"github.com/cxr29/log"
 )
 
 // Read *T returns bool, []T returns map[int]struct{}, map[]*T returns map[]struct{}.
 func (h Huge) Read(i interface{}, columns ...string) (interface{}, error) {
+	h.Querier = h.reader("")
 	t := NewTable(i)
 	v, _ := ptrElem(i)
 	if t.PrimaryKey() == nil {
@@ -23,18 +22,18 @@ func (h Huge) Read(i interface{}, columns ...string) (interface{}, error) {
 	if a.Empty() {
 		return nil, t.errNoColumns()
 	}
-	s := make([]*sql.Stmt, 2)
+	s := make([]*stmt, 2)
 	defer func() {
 		for _, j := range s {
 			if j != nil {
-				log.ErrWarning(j.Close())
+				h.logWarning(j.Close())
 			}
 		}
 	}()
 	return h.read(s, t, a, v)
 }
 
-func (h Huge) read(s []*sql.Stmt, t *Table, a Columns, v reflect.Value) (_ interface{}, err error) {
+func (h Huge) read(s []*stmt, t *Table, a Columns, v reflect.Value) (_ interface{}, err error) {
 	var b bool
 	switch v.Kind() {
 	case reflect.Map:
@@ -66,7 +65,7 @@ func (h Huge) read(s []*sql.Stmt, t *Table, a Columns, v reflect.Value) (_ inter
 	return h.read1(s, t, a, v)
 }
 
-func (h Huge) read1(s []*sql.Stmt, t *Table, a Columns, v reflect.Value) (_ bool, err error) {
+func (h Huge) read1(s []*stmt, t *Table, a Columns, v reflect.Value) (_ bool, err error) {
 	if v.Kind() == reflect.Ptr {
 		if v.IsNil() {
 			return false, t.errNil()
@@ -78,7 +77,7 @@ func (h Huge) read1(s []*sql.Stmt, t *Table, a Columns, v reflect.Value) (_ bool
 	f := make([]func() error, len(a))
 	for i, c := range a {
 		var ok bool
-		b[i], f[i], ok = c.scan(v)
+		b[i], f[i], ok = c.scan(v, h.Location)
 		if !ok {
 			return false, c.errSet()
 		}
@@ -93,14 +92,14 @@ func (h Huge) read1(s []*sql.Stmt, t *Table, a Columns, v reflect.Value) (_ bool
 		if j == 1 {
 			where.And(t.Version().Eq(2))
 		}
-		s[j], _, err = h.Prepare(query.Q(
+		s[j], err = h.prepareStmt(t.Name, query.Q(
 			query.Select(a.Strings()...), query.From(t.Name), where,
 		))
 		if err != nil {
 			return
 		}
 	}
-	if err = s[j].QueryRow(p...).Scan(b...); err == ErrNoRows {
+	if err = s[j].queryRow(p, b...); err == ErrNoRows {
 		return false, nil
 	} else if err != nil {
 		return
@@ -112,6 +111,7 @@ func (h Huge) read1(s []*sql.Stmt, t *Table, a Columns, v reflect.Value) (_ bool
 			}
 		}
 	}
+	t.takeSnapshot(v, a)
 	return true, nil
 }
 
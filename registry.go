@@ -0,0 +1,98 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sync"
+)
+
+// Mapping of a Go type by RegisterType.
+type Mapping struct {
+	// Name of the logical type passed to Starter.Mapping, like string, bytes or int64.
+	Name string
+	// DDL of the column type by dialect, overrides Starter.Mapping of Name.
+	DDL map[string]string
+	// Value to bind of the Go value, as is if nil.
+	Value func(interface{}) (driver.Value, error)
+	// Scan non NULL src into dst pointer to the Go type, by database/sql if nil.
+	Scan func(dst, src interface{}) error
+}
+
+var rm sync.RWMutex
+
+var registry = make(map[reflect.Type]*Mapping)
+
+// RegisterType m of the type of i, like uuid.UUID{}, net.IP{} or time.Duration(0),
+// before tables using it are created.
+func RegisterType(i interface{}, m Mapping) {
+	if i == nil {
+		panic("huge: nil")
+	} else if len(m.Name) == 0 {
+		panic("huge: empty type name")
+	}
+	t := reflect.TypeOf(i)
+	if t.Kind() == reflect.Ptr {
+		panic("huge: type unsupported")
+	}
+	rm.Lock()
+	registry[t] = &m
+	rm.Unlock()
+}
+
+func registered(t reflect.Type) *Mapping {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	rm.RLock()
+	m := registry[t]
+	rm.RUnlock()
+	return m
+}
+
+// values of the registered types in a, a is modified.
+func values(a []interface{}) ([]interface{}, error) {
+	for k, i := range a {
+		if i == nil {
+			continue
+		}
+		v := reflect.ValueOf(i)
+		if m := registered(v.Type()); m != nil && m.Value != nil {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					a[k] = nil
+					continue
+				}
+				i = v.Elem().Interface()
+			}
+			j, err := m.Value(i)
+			if err != nil {
+				return nil, err
+			}
+			a[k] = j
+		}
+	}
+	return a, nil
+}
+
+// scanner of f by the registered Scan into v, NULL sets the zero value.
+func (f *Field) scanner(v reflect.Value) (interface{}, func() error) {
+	var src interface{}
+	return &src, func() error {
+		if src == nil {
+			v.Set(reflect.Zero(f.t))
+			return nil
+		} else if !f.Is(oPointer) {
+			return f.x.Scan(v.Addr().Interface(), src)
+		}
+		p := reflect.New(f.t.Elem())
+		if err := f.x.Scan(p.Interface(), src); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+}
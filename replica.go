@@ -0,0 +1,69 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"database/sql"
+	"math/rand"
+	"regexp"
+	"sync/atomic"
+)
+
+// Balancer picks one of the replicas for a read.
+type Balancer func([]*sql.DB) *sql.DB
+
+func RandomBalancer(a []*sql.DB) *sql.DB {
+	return a[rand.Intn(len(a))]
+}
+
+func RoundRobinBalancer() Balancer {
+	var i uint32
+	return func(a []*sql.DB) *sql.DB {
+		return a[int((atomic.AddUint32(&i, 1)-1)%uint32(len(a)))]
+	}
+}
+
+type session struct {
+	wrote int32
+}
+
+// Session reads from the primary after the first write of the returned Huge and its copies.
+func (h Huge) Session() Huge {
+	h.session = new(session)
+	return h
+}
+
+// Primary reads from the primary.
+func (h Huge) Primary() Huge {
+	h.Replicas = nil
+	return h
+}
+
+func (h Huge) wrote() {
+	if h.session != nil {
+		atomic.StoreInt32(&h.session.wrote, 1)
+	}
+}
+
+var lockingRegexp = regexp.MustCompile(`(?i)\bFOR\s+(UPDATE|SHARE|NO\s+KEY\s+UPDATE|KEY\s+SHARE)\b|\bLOCK\s+IN\s+SHARE\s+MODE\b`)
+var selectRegexp = regexp.MustCompile(`(?i)^\s*\(*\s*SELECT\b`)
+
+func readOnly(s string) bool {
+	return selectRegexp.MatchString(s) && !lockingRegexp.MatchString(s)
+}
+
+// reader of s, empty for select without locking.
+func (h Huge) reader(s string) Querier {
+	if len(h.Replicas) == 0 || (len(s) > 0 && !readOnly(s)) {
+		return h.Querier
+	} else if _, ok := h.Querier.(*sql.DB); !ok {
+		return h.Querier
+	} else if h.session != nil && atomic.LoadInt32(&h.session.wrote) != 0 {
+		return h.Querier
+	} else if h.Balancer != nil {
+		return h.Balancer(h.Replicas)
+	}
+	return RandomBalancer(h.Replicas)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/cxr29/huge/query"
+)
+
+var ErrNoTransaction = errors.New("huge: transactions unsupported")
+
+// Retry at most Attempts times, backing off from Min to Max with jitter,
+// zero value for 3 attempts from 10ms to 1s.
+type Retry struct {
+	Attempts int
+	Min, Max time.Duration
+}
+
+func (r Retry) backoff(n int) time.Duration {
+	min, max := r.Min, r.Max
+	if min <= 0 {
+		min = 10 * time.Millisecond
+	}
+	if max < min {
+		max = time.Second
+		if max < min {
+			max = min
+		}
+	}
+	d := min
+	for i := 1; i < n && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// Retryable err classified by the Starter.
+func (h Huge) Retryable(err error) bool {
+	if c, ok := h.Starter.(query.Classifier); ok && err != nil {
+		return c.Retryable(err)
+	}
+	return false
+}
+
+// Transaction runs f in a transaction, commits if f returns nil otherwise rolls back,
+// the whole f is run again on retryable errors, returns ErrNoTransaction if the Starter lacks transactions.
+func (h Huge) Transaction(r Retry, f func(Huge) error) (err error) {
+	if !query.Supports(h.Starter, query.FeatureTransaction) {
+		return ErrNoTransaction
+	}
+	n := r.Attempts
+	if n <= 0 {
+		n = 3
+	}
+	for i := 1; ; i++ {
+		if err = h.transaction(f); err == nil || i >= n || !h.Retryable(err) {
+			return
+		}
+		time.Sleep(r.backoff(i))
+	}
+}
+
+func (h Huge) transaction(f func(Huge) error) (err error) {
+	tx, err := h.Begin()
+	if err != nil {
+		return
+	}
+	done := false
+	defer func() {
+		if !done {
+			h.logWarning(tx.Rollback())
+		}
+	}()
+	if err = f(tx); err != nil {
+		return
+	}
+	done = true
+	return tx.Commit()
+}
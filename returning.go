@@ -0,0 +1,88 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cxr29/huge/query"
+)
+
+// returning columns read back after create 'c', update 'u' or delete 'd',
+// the generated and the auto increment, default or version set by the database.
+func (t *Table) returning(b byte, auto *Column) (a Columns) {
+	for _, c := range t.a {
+		if c.isMany() {
+			continue
+		}
+		if c.isGenerated() ||
+			(b == 'c' && (c == auto || c.isDefault())) ||
+			(b == 'u' && c.isVersion()) {
+			a = append(a, c)
+		}
+	}
+	return
+}
+
+// returning clause of the columns, empty if the Starter does not support.
+func (h Huge) returning(b byte, a Columns) (output, returning string, err error) {
+	if len(a) == 0 {
+		return
+	}
+	names := make([]string, len(a))
+	for i, c := range a {
+		if names[i] = h.Starter.Quote(c.Name); len(names[i]) == 0 {
+			return "", "", c.errUnsupported()
+		}
+	}
+	s := strings.Join(names, ", ")
+	if o, ok := h.Starter.(query.Outputer); ok {
+		output = o.Output(b, s)
+	} else if returning = h.Starter.Returning(b, s); len(returning) == 0 && h.undetected() {
+		for _, c := range a {
+			if c.isDefault() || c.isGenerated() {
+				return "", "", c.err("not read back, call Detect for RETURNING")
+			}
+		}
+	}
+	return
+}
+
+// undetected if the Starter is version-aware but Detect not called.
+func (h Huge) undetected() bool {
+	switch s := h.Starter.(type) {
+	case query.SQLite:
+		return len(s.Version) == 0
+	case query.MySQL:
+		return len(s.Version) == 0
+	}
+	return false
+}
+
+// scanColumns of v to read back the columns, f must be called after scan.
+func scanColumns(a Columns, v reflect.Value, loc *time.Location) (_ []interface{}, f func() error, _ error) {
+	d := make([]interface{}, len(a))
+	var g []func() error
+	for i, c := range a {
+		j, k, ok := c.scan(v, loc)
+		if !ok {
+			return nil, nil, c.errSet()
+		}
+		d[i] = j
+		if k != nil {
+			g = append(g, k)
+		}
+	}
+	return d, func() error {
+		for _, k := range g {
+			if err := k(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
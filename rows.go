@@ -8,14 +8,21 @@ import (
 	"database/sql"
 	"errors"
 	"reflect"
-
-	"github.com/cxr29/log"
+	"time"
 )
 
+var ErrStop = errors.New("huge: stop")
+
 type Rows struct {
 	err      error
 	rows     *sql.Rows
+	logger   Logger
 	DealName func(string) string
+	loc      *time.Location
+}
+
+func (r *Rows) logWarning(err error) {
+	logWarning(r.logger, err)
 }
 
 func (r *Rows) Close() error {
@@ -72,7 +79,7 @@ func (r *Rows) scanMap(columns []string, v reflect.Value) error {
 			if i, ok := m[s]; !ok {
 				return errors.New("huge: column not exist: " + s)
 			} else {
-				a[i], f[i] = scanNew(v.MapIndex(k).Interface())
+				a[i], f[i] = scanNew(v.MapIndex(k).Interface(), r.loc)
 			}
 		}
 		for i := range a {
@@ -108,7 +115,7 @@ func (r *Rows) scanSlice(n int, v reflect.Value) error {
 	f := make([]scanNewFunc, n)
 	if t := v.Type().Elem(); t == typeInterface {
 		for i := 0; i < n; i++ {
-			a[i], f[i] = scanNew(v.Index(i).Interface())
+			a[i], f[i] = scanNew(v.Index(i).Interface(), r.loc)
 		}
 	} else {
 		for i := 0; i < n; i++ {
@@ -132,22 +139,36 @@ func (r *Rows) scanSlice(n int, v reflect.Value) error {
 	return nil
 }
 
-func (r *Rows) scanStruct(t *Table, columns []string, v reflect.Value) error {
+func (r *Rows) structColumns(t *Table, columns []string) (Columns, error) {
+	a := make(Columns, len(columns))
 	m := make(map[int]struct{}, len(columns))
-	a := make([]interface{}, len(columns))
-	f := make([]func() error, len(columns))
 	for i, s := range columns {
 		if r.DealName != nil {
 			s = r.DealName(s)
 		}
 		if c := t.Find(s); c == nil {
-			return errors.New("huge: column not found: " + s)
+			return nil, errors.New("huge: column not found: " + s)
 		} else if _, ok := m[c.i]; ok {
-			return errors.New("huge: duplicate column: " + s)
-		} else if a[i], f[i], ok = c.scan(v); ok {
-			m[c.i] = struct{}{}
+			return nil, errors.New("huge: duplicate column: " + s)
 		} else {
-			return c.errSet()
+			a[i] = c
+			m[c.i] = struct{}{}
+		}
+	}
+	return a, nil
+}
+
+func (r *Rows) scanStruct(t *Table, columns []string, v reflect.Value) error {
+	c, err := r.structColumns(t, columns)
+	if err != nil {
+		return err
+	}
+	a := make([]interface{}, len(c))
+	f := make([]func() error, len(c))
+	for i := range c {
+		var ok bool
+		if a[i], f[i], ok = c[i].scan(v, r.loc); !ok {
+			return c[i].errSet()
 		}
 	}
 	if err := r.rows.Scan(a...); err != nil {
@@ -163,7 +184,7 @@ func (r *Rows) scanStruct(t *Table, columns []string, v reflect.Value) error {
 	return nil
 }
 
-// Scan T, [] or map[string]. For straightforward Scan just given an array.
+// Scan T, [] or map[string], or a Scanner struct of one column. For straightforward Scan just given an array.
 func (r *Rows) Scan(i interface{}) error {
 	if r.err != nil {
 		return r.err
@@ -205,6 +226,9 @@ func (r *Rows) Scan(i interface{}) error {
 			panic("huge: length")
 		}
 	case reflect.Struct:
+		if len(columns) == 1 && reflect.PtrTo(v.Type()).Implements(typeScanner) && v.CanAddr() {
+			return r.rows.Scan(v.Addr().Interface()) // like Decimal of Sum
+		}
 		return r.scanStruct(newTableBy(v.Type()), columns, v)
 	}
 	panic("huge: type unsupported")
@@ -216,7 +240,7 @@ func (r *Rows) One(i interface{}) (ok bool, err error) {
 		return false, r.err
 	}
 	defer func() {
-		log.ErrWarning(r.rows.Close())
+		r.logWarning(r.rows.Close())
 	}()
 	if r.rows.Next() {
 		err = r.Scan(i)
@@ -243,7 +267,7 @@ Loop:
 	for r.rows.Next() {
 		for i, j := range a {
 			if j != nil {
-				b[i], f[i] = scanNew(j)
+				b[i], f[i] = scanNew(j, r.loc)
 			} else {
 				b[i], f[i] = reflect.New(t).Interface(), nil
 			}
@@ -288,40 +312,16 @@ Loop:
 	return err
 }
 
-func (r *Rows) allStruct(columns []string, t *Table, v reflect.Value) (err error) {
-	a := make(Columns, len(columns))
-	m := make(map[int]struct{}, len(columns))
-	for i, s := range columns {
-		if r.DealName != nil {
-			s = r.DealName(s)
-		}
-		if c := t.Find(s); c == nil {
-			return errors.New("huge: column not found: " + s)
-		} else if _, ok := m[c.i]; ok {
-			return errors.New("huge: duplicate column: " + s)
-		} else {
-			a[i] = c
-			m[c.i] = struct{}{}
-		}
-	}
-	c := t.PrimaryKey()
-	x := v.Kind() == reflect.Map
-	y := v.Type().Elem().Kind() == reflect.Ptr
-	if x {
-		if _, ok := m[c.i]; !ok {
-			return errors.New("huge: primary key column not exist")
-		}
-	} else if v.Len() > 0 {
-		v.SetLen(0)
-	}
+func (r *Rows) each(t *Table, a Columns, g func(reflect.Value) error) (err error) {
 	b := make([]interface{}, len(a))
 	f := make([]func() error, len(a))
+Loop:
 	for r.rows.Next() {
 		p := reflect.New(t.s.t)
 		q := p.Elem()
 		for i, c := range a {
 			var ok bool
-			b[i], f[i], ok = c.scan(q)
+			b[i], f[i], ok = c.scan(q, r.loc)
 			if !ok {
 				return c.errSet()
 			}
@@ -332,31 +332,17 @@ func (r *Rows) allStruct(columns []string, t *Table, v reflect.Value) (err error
 		for _, i := range f {
 			if i != nil {
 				if err = i(); err != nil {
-					break
+					break Loop
 				}
 			}
 		}
-		if x {
-			if k, ok := c.field(q); ok {
-				if v.MapIndex(k).IsValid() {
-					err = c.errDuplicate()
-					break
-				} else if y {
-					v.SetMapIndex(k, p)
-				} else {
-					v.SetMapIndex(k, q)
-				}
-			} else {
-				err = c.errGet()
-				break
-			}
-		} else if y {
-			v.Set(reflect.Append(v, p))
-		} else {
-			v.Set(reflect.Append(v, q))
+		if err = g(p); err != nil {
+			break
 		}
 	}
-	if err == nil {
+	if err == ErrStop {
+		err = nil
+	} else if err == nil {
 		err = r.Err()
 	}
 	if err == nil {
@@ -365,13 +351,56 @@ func (r *Rows) allStruct(columns []string, t *Table, v reflect.Value) (err error
 	return err
 }
 
+func (r *Rows) allStruct(columns []string, t *Table, v reflect.Value) error {
+	a, err := r.structColumns(t, columns)
+	if err != nil {
+		return err
+	}
+	c := t.PrimaryKey()
+	x := v.Kind() == reflect.Map
+	y := v.Type().Elem().Kind() == reflect.Ptr
+	if x {
+		ok := false
+		for _, i := range a {
+			if i == c {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return errors.New("huge: primary key column not exist")
+		}
+	} else if v.Len() > 0 {
+		v.SetLen(0)
+	}
+	return r.each(t, a, func(p reflect.Value) error {
+		q := p.Elem()
+		if x {
+			if k, ok := c.field(q); !ok {
+				return c.errGet()
+			} else if v.MapIndex(k).IsValid() {
+				return c.errDuplicate()
+			} else if y {
+				v.SetMapIndex(k, p)
+			} else {
+				v.SetMapIndex(k, q)
+			}
+		} else if y {
+			v.Set(reflect.Append(v, p))
+		} else {
+			v.Set(reflect.Append(v, q))
+		}
+		return nil
+	})
+}
+
 // All *[]T, map[PK]T, *[][] or *[]map[string].
 func (r *Rows) All(i interface{}) error {
 	if r.err != nil {
 		return r.err
 	}
 	defer func() {
-		log.ErrWarning(r.rows.Close())
+		r.logWarning(r.rows.Close())
 	}()
 	v, p := ptrElem(i)
 	columns, err := r.Columns()
@@ -447,3 +476,90 @@ func (r *Rows) All(i interface{}) error {
 	}
 	panic("huge: type unsupported")
 }
+
+// Each row scan into a new T and call f of func(*T) error,
+// stop on the first error, ErrStop for no error, and Close.
+func (r *Rows) Each(f interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer func() {
+		r.logWarning(r.rows.Close())
+	}()
+	v := reflect.ValueOf(f)
+	if !v.IsValid() || v.Kind() != reflect.Func {
+		panic("huge: not func(*T) error")
+	}
+	x := v.Type()
+	if x.NumIn() != 1 || x.NumOut() != 1 || x.Out(0) != typeError ||
+		x.In(0).Kind() != reflect.Ptr || x.In(0).Elem().Kind() != reflect.Struct {
+		panic("huge: not func(*T) error")
+	} else if v.IsNil() {
+		panic("huge: nil")
+	}
+	t := newTableBy(x.In(0))
+	columns, err := r.rows.Columns()
+	if err != nil {
+		return err
+	}
+	a, err := r.structColumns(t, columns)
+	if err != nil {
+		return err
+	}
+	return r.each(t, a, func(p reflect.Value) error {
+		if e := v.Call([]reflect.Value{p})[0]; !e.IsNil() {
+			return e.Interface().(error)
+		}
+		return nil
+	})
+}
+
+// Send each row as a new *T or T to ch of chan *T or chan T until done is closed,
+// ch is closed on return.
+func (r *Rows) Send(ch interface{}, done <-chan struct{}) error {
+	v := reflect.ValueOf(ch)
+	if !v.IsValid() || v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.SendDir == 0 {
+		panic("huge: not chan")
+	} else if v.IsNil() {
+		panic("huge: nil")
+	}
+	defer v.Close()
+	if r.err != nil {
+		return r.err
+	}
+	defer func() {
+		r.logWarning(r.rows.Close())
+	}()
+	x := v.Type().Elem()
+	y := x.Kind() == reflect.Ptr
+	if y {
+		x = x.Elem()
+	}
+	if x.Kind() != reflect.Struct {
+		panic("huge: type unsupported")
+	}
+	t := newTableBy(x)
+	columns, err := r.rows.Columns()
+	if err != nil {
+		return err
+	}
+	a, err := r.structColumns(t, columns)
+	if err != nil {
+		return err
+	}
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: v},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)},
+	}
+	return r.each(t, a, func(p reflect.Value) error {
+		if y {
+			cases[0].Send = p
+		} else {
+			cases[0].Send = p.Elem()
+		}
+		if i, _, _ := reflect.Select(cases); i == 1 {
+			return ErrStop
+		}
+		return nil
+	})
+}
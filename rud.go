@@ -7,10 +7,8 @@ package huge
 import (
 	"database/sql"
 	"reflect"
-	"time"
 
 	"github.com/cxr29/huge/query"
-	"github.com/cxr29/log"
 )
 
 func (h Huge) rud(b byte, primaryKeys, row interface{}, columns []string) (_ interface{}, n int64, err error) {
@@ -40,6 +38,11 @@ func (h Huge) rud(b byte, primaryKeys, row interface{}, columns []string) (_ int
 			return nil, 0, t.errNoColumns()
 		}
 	}
+	if b != 'r' {
+		if err = h.checkVersion(t); err != nil {
+			return
+		}
+	}
 	i, j, err := t.getVersion(v)
 	if err != nil {
 		return
@@ -120,6 +123,8 @@ func (h Huge) rud(b byte, primaryKeys, row interface{}, columns []string) (_ int
 	where := query.Where()
 	if len(a) == 1 {
 		where.And(c.Eq(a[0]))
+	} else if p, ok := anyArray(h.Starter, a); ok {
+		where.And(c.Any(p))
 	} else {
 		where.And(c.In(a...))
 	}
@@ -136,12 +141,12 @@ func (h Huge) rud(b byte, primaryKeys, row interface{}, columns []string) (_ int
 			return
 		}
 		var r *sql.Rows
-		r, err = h.Querier.Query(s, a...)
+		r, err = h.query(t.Name, s, a)
 		if err != nil {
 			return
 		}
 		defer func() {
-			log.ErrWarning(r.Close())
+			h.logWarning(r.Close())
 		}()
 		switch n {
 		case 0:
@@ -161,7 +166,7 @@ func (h Huge) rud(b byte, primaryKeys, row interface{}, columns []string) (_ int
 			q := p.Elem()
 			for i, c := range cols {
 				var ok bool
-				b[i], f[i], ok = c.scan(q)
+				b[i], f[i], ok = c.scan(q, h.Location)
 				if !ok {
 					err = c.errSet()
 					break Loop
@@ -205,7 +210,8 @@ func (h Huge) rud(b byte, primaryKeys, row interface{}, columns []string) (_ int
 		}
 		return v.Interface(), n, err
 	case 'u':
-		set, now := query.X.Set(), time.Now()
+		update, set := h.updateSet(t.Name)
+		now := h.now()
 		for _, c := range cols {
 			if c.isVersion() {
 				set.Add(c.Name, c.Inc())
@@ -222,11 +228,11 @@ func (h Huge) rud(b byte, primaryKeys, row interface{}, columns []string) (_ int
 			}
 		}
 		var r sql.Result
-		r, err = h.Exec(query.Q(
-			query.Update(t.Name), set, where,
+		r, err = h.exec(t.Name, query.Q(
+			update, set, where,
 		))
 		if err == nil {
-			if n, err = r.RowsAffected(); err == nil && n > 0 {
+			if n, err = h.rowsAffected(r, int64(len(a))); err == nil && n > 0 {
 				c := t.Version()
 				if i > 0 && !c.setInteger(v, i+1) {
 					return nil, 0, c.errSet()
@@ -239,13 +245,32 @@ func (h Huge) rud(b byte, primaryKeys, row interface{}, columns []string) (_ int
 		return
 	case 'd':
 		var r sql.Result
-		r, err = h.Exec(query.Q(
-			query.Delete(t.Name), where,
+		r, err = h.exec(t.Name, query.Q(
+			h.deleteFrom(t.Name), where,
 		))
 		if err == nil {
-			n, err = r.RowsAffected()
+			n, err = h.rowsAffected(r, int64(len(a)))
 		}
 		return
 	}
 	panic(false)
 }
+
+// anyArray of PostgreSQL for = ANY instead of IN, keeps the SQL the same for any number of keys.
+func anyArray(s query.Starter, a []interface{}) (query.Array, bool) {
+	if s.Dialect() != "postgres" || a[0] == nil {
+		return query.Array{}, false
+	}
+	t := reflect.TypeOf(a[0])
+	v := reflect.MakeSlice(reflect.SliceOf(t), len(a), len(a))
+	if !query.IsArray(v.Type()) {
+		return query.Array{}, false
+	}
+	for i, j := range a {
+		if j == nil || reflect.TypeOf(j) != t {
+			return query.Array{}, false
+		}
+		v.Index(i).Set(reflect.ValueOf(j))
+	}
+	return query.Array{V: v.Interface()}, true
+}
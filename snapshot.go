@@ -0,0 +1,146 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"reflect"
+	"time"
+)
+
+// Snapshot embedded in T opts in to change tracking,
+// Create, Read and Update take a snapshot of the column values,
+// then Update without columns sets only the changed columns, plus auto_now and version,
+// and skips the statement if nothing changed.
+type Snapshot struct {
+	a []interface{}
+}
+
+// Reset the snapshot, the next Update sets all columns.
+func (s *Snapshot) Reset() {
+	s.a = nil
+}
+
+// Taken reports whether the snapshot is taken.
+func (s *Snapshot) Taken() bool {
+	return s.a != nil
+}
+
+var typeSnapshot = reflect.TypeOf(Snapshot{})
+
+func (t *Table) snapshot(v reflect.Value) *Snapshot {
+	if t.s.snap == 0 || v.Kind() != reflect.Struct || !v.CanAddr() {
+		return nil
+	}
+	return v.Field(t.s.snap - 1).Addr().Interface().(*Snapshot)
+}
+
+// snapshotValue of c in v without encryption.
+func (c *Column) snapshotValue(v reflect.Value) (interface{}, error) {
+	v, ok := c.field(v)
+	if !ok {
+		if c.isCollapse() {
+			return nil, nil
+		}
+		return nil, c.errGet()
+	}
+	i, err := c.plain(true, true, v)
+	if err != nil {
+		return nil, err
+	}
+	if x, ok := i.(driver.Valuer); ok {
+		if i, err = x.Value(); err != nil {
+			return nil, err
+		}
+	}
+	if b, ok := i.([]byte); ok && b != nil {
+		i = append([]byte{}, b...)
+	}
+	return i, nil
+}
+
+// takeSnapshot of the columns a of v if tracking, all if nil,
+// a new snapshot needs all columns Update sets, the snapshot is reset on error.
+func (t *Table) takeSnapshot(v reflect.Value, a Columns) {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	s := t.snapshot(v)
+	if s == nil {
+		return
+	}
+	if a == nil {
+		a = t.a
+	}
+	b := s.a
+	if b == nil {
+		m := make(map[int]struct{}, len(a))
+		for _, c := range a {
+			m[c.i] = struct{}{}
+		}
+		for _, c := range t.updateFilter() {
+			if _, ok := m[c.i]; !ok {
+				return
+			}
+		}
+		b = make([]interface{}, len(t.a))
+	} else {
+		b = append([]interface{}{}, b...) // copies of T share the old one
+	}
+	for _, c := range a {
+		if c.isMany() {
+			continue
+		}
+		var err error
+		if b[c.i], err = c.snapshotValue(v); err != nil {
+			s.a = nil
+			return
+		}
+	}
+	s.a = b
+}
+
+// changed columns of a in v since the snapshot, with auto_now and version if any changed,
+// false if no snapshot.
+func (t *Table) changed(v reflect.Value, a Columns) (Columns, bool, error) {
+	s := t.snapshot(v)
+	if s == nil || len(s.a) != len(t.a) {
+		return nil, false, nil
+	}
+	d := make(Columns, 0, len(a))
+	for _, c := range a {
+		if c.isAutoNow() || c.isVersion() {
+			continue
+		}
+		i, err := c.snapshotValue(v)
+		if err != nil {
+			return nil, true, err
+		}
+		if !sameValue(i, s.a[c.i]) {
+			d = append(d, c)
+		}
+	}
+	if len(d) > 0 {
+		for _, c := range a {
+			if c.isAutoNow() || c.isVersion() {
+				d = append(d, c)
+			}
+		}
+	}
+	return d, true, nil
+}
+
+func sameValue(i, j interface{}) bool {
+	switch x := i.(type) {
+	case []byte:
+		y, ok := j.([]byte)
+		return ok && (x == nil) == (y == nil) && bytes.Equal(x, y)
+	case time.Time:
+		y, ok := j.(time.Time)
+		return ok && x.Equal(y)
+	}
+	return reflect.DeepEqual(i, j)
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2017 CHEN Xianren. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package huge
+
+import (
+	"container/list"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/cxr29/huge/query"
+)
+
+type stmt struct {
+	h     Huge
+	p     *sql.Stmt
+	table string
+	sql   string
+	e     *stmtEntry
+}
+
+func (h Huge) prepareStmt(table string, q query.Expression) (*stmt, error) {
+	s, _, err := h.Expand(q)
+	if err != nil {
+		return nil, err
+	}
+	if h.Stmts != nil {
+		db, ok := h.Querier.(*sql.DB)
+		tx, _ := h.Querier.(*sql.Tx)
+		if !ok && tx != nil {
+			db = h.db
+		}
+		if db != nil {
+			k := stmtKey{db, h.Starter.Dialect(), table, s}
+			e := h.Stmts.get(k)
+			if e == nil {
+				p, err := h.prepare(db, table, s)
+				if err != nil {
+					return nil, err
+				}
+				var a []*sql.Stmt
+				e, a = h.Stmts.put(k, p)
+				for _, p := range a {
+					h.logWarning(p.Close())
+				}
+			}
+			x := &stmt{h, e.p, table, s, e}
+			if tx != nil {
+				x.p = tx.Stmt(e.p)
+			}
+			return x, nil
+		}
+	}
+	p, err := h.prepare(h.Querier, table, s)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{h, p, table, s, nil}, nil
+}
+
+func (s *stmt) Close() (err error) {
+	if s.e == nil {
+		return s.p.Close()
+	}
+	if s.p != s.e.p {
+		err = s.p.Close()
+	}
+	if e := s.h.Stmts.release(s.e); err == nil {
+		err = e
+	}
+	return
+}
+
+func (s *stmt) Exec(a ...interface{}) (r sql.Result, err error) {
+	if a, err = s.h.bind(a); err != nil {
+		return
+	}
+	c := &Call{Op: OpExec, Table: s.table, SQL: s.sql, Args: a}
+	err = s.h.intercept(c, func() (err error) {
+		defer s.h.logSlow(s.sql, a, time.Now())
+		s.h.wrote()
+		if r, err = s.p.Exec(a...); err == nil && len(s.h.Interceptors) > 0 {
+			c.RowsAffected, _ = r.RowsAffected()
+		}
+		return
+	})
+	return
+}
+
+func (s *stmt) queryRow(a []interface{}, b ...interface{}) (err error) {
+	if a, err = s.h.bind(a); err != nil {
+		return
+	}
+	return s.h.intercept(&Call{Op: OpQueryRow, Table: s.table, SQL: s.sql, Args: a}, func() error {
+		defer s.h.logSlow(s.sql, a, time.Now())
+		if !readOnly(s.sql) {
+			s.h.wrote()
+		}
+		return s.p.QueryRow(a...).Scan(b...)
+	})
+}
+
+type stmtKey struct {
+	db                  *sql.DB
+	dialect, table, sql string
+}
+
+type stmtEntry struct {
+	k       stmtKey
+	p       *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// StmtCache is a LRU cache of prepared statements shared by copies of a Huge,
+// the statements are re-bound to the transaction after Begin.
+type StmtCache struct {
+	mu  sync.Mutex
+	max int
+	l   *list.List
+	m   map[stmtKey]*list.Element
+}
+
+func NewStmtCache(max int) *StmtCache {
+	if max <= 0 {
+		panic("huge: max")
+	}
+	return &StmtCache{max: max, l: list.New(), m: make(map[stmtKey]*list.Element, max)}
+}
+
+func (c *StmtCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.l.Len()
+}
+
+func (c *StmtCache) get(k stmtKey) *stmtEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if i, ok := c.m[k]; ok {
+		c.l.MoveToFront(i)
+		e := i.Value.(*stmtEntry)
+		e.refs++
+		return e
+	}
+	return nil
+}
+
+// put returns the entry and the statements to close.
+func (c *StmtCache) put(k stmtKey, p *sql.Stmt) (*stmtEntry, []*sql.Stmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if i, ok := c.m[k]; ok {
+		c.l.MoveToFront(i)
+		e := i.Value.(*stmtEntry)
+		e.refs++
+		return e, []*sql.Stmt{p}
+	}
+	e := &stmtEntry{k: k, p: p, refs: 1}
+	c.m[k] = c.l.PushFront(e)
+	var a []*sql.Stmt
+	for c.l.Len() > c.max {
+		if p := c.evict(c.l.Back()); p != nil {
+			a = append(a, p)
+		}
+	}
+	return e, a
+}
+
+func (c *StmtCache) evict(i *list.Element) *sql.Stmt {
+	e := c.l.Remove(i).(*stmtEntry)
+	delete(c.m, e.k)
+	e.evicted = true
+	if e.refs == 0 {
+		return e.p
+	}
+	return nil
+}
+
+func (c *StmtCache) release(e *stmtEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.refs--; e.refs == 0 && e.evicted {
+		return e.p.Close()
+	}
+	return nil
+}
+
+// Close all statements not in use and empty the cache, the ones in use are closed when released.
+func (c *StmtCache) Close() (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.l.Len() > 0 {
+		if p := c.evict(c.l.Back()); p != nil {
+			if e := p.Close(); err == nil {
+				err = e
+			}
+		}
+	}
+	return
+}
@@ -19,6 +19,7 @@ type Struct struct {
 	t    reflect.Type
 	a    []*Field
 	name string
+	snap int // index plus 1 of the Snapshot field
 }
 
 var structs = make(map[reflect.Type]*Struct)
@@ -71,12 +72,18 @@ func (s *Struct) fire() error {
 		t := f.Tag.Get("huge")
 		if t == "-" {
 			continue
+		} else if f.Type == typeSnapshot {
+			s.snap = i + 1
+			continue
 		}
-		e, t, o, size := parseOptions(f.Type, t)
+		e, t, o, size, scale, c := parseOptions(f.Type, t)
 		if len(e) > 0 {
 			return fmt.Errorf("huge: struct %s field:%d %s: %s", s.name, i+1, f.Name, e)
 		}
-		v := &Field{t: f.Type, o: o, i: i, size: size, belong: s, name: f.Name, alias: t}
+		v := &Field{t: f.Type, o: o, i: i, size: size, scale: scale, c: c, belong: s, name: f.Name, alias: t}
+		if v.x = registered(f.Type); v.x != nil {
+			v.o &^= oArray
+		}
 		if v.IsInline() || v.IsOne() || v.IsMany() {
 			t := elemStruct(v.t)
 			s, ok := structs[t]
@@ -134,7 +141,7 @@ func (t *Table) fire() error {
 		}
 	}
 	a := make([]string, 0, 5)
-	t.o = make(map[uint]int, 5)
+	t.o = make(map[uint64]int, 5)
 	t.m = make(map[string]int, len(t.a))
 	for _, c := range t.a {
 		a = a[:0]
@@ -158,7 +165,7 @@ func (t *Table) fire() error {
 			if f.Is(oUnique) {
 				c.o |= oUnique
 			}
-			for _, u := range [...]uint{oAutoIncrement, oAutoNow, oAutoNowAdd, oPrimaryKey, oVersion} {
+			for _, u := range [...]uint64{oAutoIncrement, oAutoNow, oAutoNowAdd, oPrimaryKey, oVersion} {
 				if f.Is(u) {
 					if f.IsMany() {
 						panic(false)
@@ -229,7 +236,7 @@ func (t *Table) fire() error {
 					if r.a[i].last().IsOne() {
 						r = r.a[i].r
 						if _, ok := m[r.s.t]; ok {
-							for _, u := range [...]uint{oForeignKey, oManyToOne, oOneToOne} {
+							for _, u := range [...]uint64{oForeignKey, oManyToOne, oOneToOne} {
 								if f.Is(u) {
 									return fmt.Errorf("huge: table %s column:%d %s: %s circle",
 										t.Name, c.first().i+1, c.first().name, option(u))
@@ -332,10 +339,20 @@ func newTable(i interface{}) (_ *Table, a [2]reflect.Type) {
 	panic("huge: type unsupported")
 }
 func newTableBy(t reflect.Type) *Table {
-	s, err := newStruct(t)
+	v, err := tableOf(t)
 	if err != nil {
 		panic(err)
 	}
+	return v
+}
+func tableOf(t reflect.Type) (*Table, error) {
+	if t == nil || elemStruct(t).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("huge: type unsupported: %v", t)
+	}
+	s, err := newStruct(t)
+	if err != nil {
+		return nil, err
+	}
 	tm.RLock()
 	v, ok := tables[s.t]
 	tm.RUnlock()
@@ -344,22 +361,22 @@ func newTableBy(t reflect.Type) *Table {
 		defer tm.Unlock()
 		v, ok = tables[s.t]
 		if ok {
-			return v
+			return v, nil
 		}
 		v = &Table{s: s}
 		tables[v.s.t] = v
 		if err := v.fire(); err != nil {
 			delete(tables, v.s.t)
-			panic(err)
+			return nil, err
 		}
 	}
-	return v
+	return v, nil
 }
 
 type Table struct {
 	s    *Struct
 	a    []*Column
-	o    map[uint]int
+	o    map[uint64]int
 	m    map[string]int
 	Name string
 	query.Operand
@@ -420,6 +437,15 @@ func (a Columns) Empty() bool {
 	return len(a) == 0
 }
 
+func (a Columns) has(c *Column) bool {
+	for _, i := range a {
+		if i == c {
+			return true
+		}
+	}
+	return false
+}
+
 func (a Columns) Len() int {
 	return len(a)
 }
@@ -502,7 +528,7 @@ func (t *Table) updateFilter(columns ...string) Columns {
 	a := make(Columns, 0, len(t.a))
 	if len(columns) == 0 {
 		for _, c := range t.a {
-			if c.isMany() || c.isAutoIncrement() || c.isAutoNowAdd() || c.isPrimaryKey() {
+			if c.isMany() || c.isAutoIncrement() || c.isAutoNowAdd() || c.isPrimaryKey() || c.isGenerated() {
 				continue
 			}
 			a = append(a, c)
@@ -516,7 +542,7 @@ func (t *Table) updateFilter(columns ...string) Columns {
 			}
 		}
 		for _, c := range t.a {
-			if c.isMany() {
+			if c.isMany() || c.isGenerated() {
 				continue
 			}
 			ok := c.isAutoNow() || c.isVersion()
@@ -616,15 +642,27 @@ func (t *Table) CreateTable(s query.Starter, temporary, ifNotExists bool) (strin
 		} else if c.isVersion() {
 			option = query.OptionVersion
 		}
-		dbType, optionValue := s.Mapping(c.Name, goType, f.size, option)
-		if len(dbType) == 0 {
+		size := f.size
+		if goType == "text" && size == 0 {
+			size = -1 // unlimited
+		}
+		dbType, optionValue := s.Mapping(c.Name, goType, size, option)
+		if f.Type() == typeDate && !f.Is(oDate) && (c.isAutoNow() || c.isAutoNowAdd()) {
+			optionValue = "" // yyyymmdd, not unix seconds
+		}
+		if f.x != nil && !f.IsEncoding() {
+			if x, ok := f.x.DDL[s.Dialect()]; ok {
+				dbType, optionValue = x, ""
+			}
+		}
+		if len(dbType) == 0 || (f.Is(oArray) && s.Dialect() != "postgres") {
 			return "", c.err("unsupported type: " + goType)
 		}
 		a = append(a, dbType)
 		if c.isPrimaryKey() {
 			a = append(a, "PRIMARY KEY")
 		} else {
-			if !c.canNil() && !c.isCollapse() {
+			if !c.isNullable() {
 				a = append(a, "NOT NULL")
 			}
 			if c.is(oUnique) {
@@ -643,8 +681,27 @@ func (t *Table) CreateTable(s query.Starter, temporary, ifNotExists bool) (strin
 	if len(columns) == 0 {
 		return "", t.errNoColumns()
 	}
+	fulltext := t.fulltext()
+	if len(fulltext) > 0 && s.Dialect() == "mysql" {
+		a = a[:0]
+		for _, c := range fulltext {
+			a = append(a, s.Quote(c.Name))
+		}
+		columns = append(columns, "FULLTEXT ("+strings.Join(a, ", ")+")")
+		fulltext = nil
+	}
+	var q string
 	if c, ok := s.(query.Creater); ok {
-		return c.CreateTable(tableName, columns, temporary, ifNotExists), nil
+		q = c.CreateTable(tableName, columns, temporary, ifNotExists)
+	} else {
+		q = query.CreateTable(tableName, columns, temporary, ifNotExists)
+	}
+	if len(fulltext) > 0 {
+		x, err := t.createFulltext(s, tableName, fulltext, ifNotExists)
+		if err != nil {
+			return "", err
+		}
+		q += x
 	}
-	return query.CreateTable(tableName, columns, temporary, ifNotExists), nil
+	return q, nil
 }
@@ -5,6 +5,7 @@
 package huge
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -12,15 +13,27 @@ import (
 	"time"
 )
 
+var ErrNaiveTime = errors.New("huge: naive time of time.Local")
+
 func (c *Column) convertTime(t time.Time, prec int) interface{} {
 	f := c.last()
 	if x := f.Type(); x == typeTime {
-		t = LimitTime(t, prec)
-		if f.Is(oPointer) {
+		t = f.truncTime(limitTime(t, prec))
+		if f.Is(oDate) || f.Is(oTimeOfDay) {
+			return f.formatTime(t)
+		} else if f.Is(oPointer) {
 			return &t
 		} else {
 			return t
 		}
+	} else if x == typeDate {
+		if d := DateOf(t); f.Is(oDate) {
+			return d.String()
+		} else if f.Is(oPointer) {
+			return &d
+		} else {
+			return d
+		}
 	} else {
 		switch x.Kind() {
 		case reflect.Int:
@@ -70,7 +83,9 @@ func (c *Column) setTime(v reflect.Value, t time.Time, prec int) bool {
 		f := c.last()
 		x := f.Type()
 		if x == typeTime {
-			i = LimitTime(t, prec)
+			i = f.truncTime(limitTime(t, prec))
+		} else if x == typeDate {
+			i = DateOf(t)
 		} else {
 			switch x.Kind() {
 			case reflect.Int:
@@ -107,6 +122,102 @@ func (c *Column) setTime(v reflect.Value, t time.Time, prec int) bool {
 	return false
 }
 
+// truncTime to the date at midnight or the time of day on January 1, year 0 in UTC.
+func (f *Field) truncTime(t time.Time) time.Time {
+	if f.Is(oDate) {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	} else if f.Is(oTimeOfDay) {
+		return time.Date(0, 1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	return t
+}
+
+func (f *Field) formatTime(t time.Time) string {
+	if f.Is(oDate) {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("15:04:05.999999999")
+}
+
+// isTime scanned by scanTime, the date, time of day, or time to convert to loc.
+func (f *Field) isTime(loc *time.Location) bool {
+	return f.Type() == typeTime && (f.Is(oDate) || f.Is(oTimeOfDay) || (loc != nil && !f.IsEncoding()))
+}
+
+var timeLayouts = [...]string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// parseTime of src, times without zone are in UTC, dates are at midnight of loc or UTC.
+func (f *Field) parseTime(src interface{}, loc *time.Location) (t time.Time, err error) {
+	var s string
+	switch x := src.(type) {
+	case time.Time:
+		t = x
+	case []byte:
+		s = string(x)
+	case string:
+		s = x
+	default:
+		return t, fmt.Errorf("huge: time scan unsupported: %T", src)
+	}
+	if _, ok := src.(time.Time); !ok {
+		if f.Is(oDate) {
+			if len(s) > 10 {
+				s = s[:10]
+			}
+			t, err = time.Parse("2006-01-02", s)
+		} else if f.Is(oTimeOfDay) {
+			t, err = time.Parse("15:04:05.999999999", s)
+		} else {
+			for _, l := range timeLayouts {
+				if t, err = time.Parse(l, s); err == nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+	if f.Is(oDate) {
+		if loc == nil {
+			loc = time.UTC
+		}
+		y, m, d := t.Date()
+		t = time.Date(y, m, d, 0, 0, 0, 0, loc)
+	} else if f.Is(oTimeOfDay) {
+		t = f.truncTime(t)
+	} else if loc != nil {
+		t = t.In(loc)
+	}
+	return
+}
+
+// scanTime into v, NULL sets the zero value.
+func (f *Field) scanTime(v reflect.Value, loc *time.Location) (interface{}, func() error) {
+	var src interface{}
+	return &src, func() error {
+		if src == nil {
+			v.Set(reflect.Zero(f.t))
+			return nil
+		}
+		t, err := f.parseTime(src, loc)
+		if err != nil {
+			return err
+		} else if f.Is(oPointer) {
+			v.Set(reflect.ValueOf(&t))
+		} else {
+			v.Set(reflect.ValueOf(t))
+		}
+		return nil
+	}
+}
+
 func Now(prec int) time.Time {
 	return LimitTime(time.Now(), prec)
 }
@@ -124,6 +235,11 @@ func LimitTime(t time.Time, prec int) time.Time {
 	}
 }
 
+// limitTime like LimitTime but kept in the location of t.
+func limitTime(t time.Time, prec int) time.Time {
+	return LimitTime(t, prec).In(t.Location())
+}
+
 const (
 	Millisecond = 1
 	Second      = 1e3 * Millisecond
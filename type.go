@@ -36,6 +36,7 @@ var (
 	typeString      = reflect.TypeOf("")
 	typeTime        = reflect.TypeOf(time.Time{})
 	typeInterface   = reflect.TypeOf(([]interface{})(nil)).Elem()
+	typeError       = reflect.TypeOf((*error)(nil)).Elem()
 	typeNullBool    = reflect.TypeOf(sql.NullBool{})
 	typeNullInt64   = reflect.TypeOf(sql.NullInt64{})
 	typeNullFloat64 = reflect.TypeOf(sql.NullFloat64{})
@@ -96,7 +97,8 @@ func (k Kind) scanNew() (interface{}, scanNewFunc) {
 	panic("huge: type unsupported")
 }
 
-func scanNew(i interface{}) (interface{}, scanNewFunc) {
+// scanNew of i, times of a column converted to loc if not nil.
+func scanNew(i interface{}, loc *time.Location) (interface{}, scanNewFunc) {
 	switch x := i.(type) {
 	case nil:
 		var j interface{}
@@ -104,7 +106,7 @@ func scanNew(i interface{}) (interface{}, scanNewFunc) {
 	case Kind:
 		return x.scanNew()
 	case *Column:
-		return x.scanNew()
+		return x.scanNew(loc)
 	case *Kind, Column, Table, *Table, Columns, *Columns:
 		panic("huge: type unsupported")
 	}
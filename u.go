@@ -5,13 +5,11 @@
 package huge
 
 import (
-	"database/sql"
 	"fmt"
 	"reflect"
 	"time"
 
 	"github.com/cxr29/huge/query"
-	"github.com/cxr29/log"
 )
 
 // Update T returns bool, []T returns map[int]struct{}, map[]T returns map[]struct{}.
@@ -25,33 +23,34 @@ func (h Huge) Update(i interface{}, columns ...string) (interface{}, error) {
 	if a.Empty() {
 		return nil, t.errNoColumns()
 	}
-	var returning string
-	if c := t.Version(); c != nil {
-		if name := h.Starter.Quote(c.Name); len(name) == 0 {
-			return nil, c.errUnsupported()
-		} else {
-			returning = h.Starter.Returning('u', name)
-		}
+	r := t.returning('u', nil)
+	output, returning, err := h.returning('u', r)
+	if err != nil {
+		return nil, err
+	} else if len(output) > 0 {
+		returning = output
+	} else if len(returning) == 0 {
+		r = nil
 	}
-	s := make([]*sql.Stmt, 2)
+	s := make([]*stmt, 2)
 	defer func() {
 		for _, j := range s {
 			if j != nil {
-				log.ErrWarning(j.Close())
+				h.logWarning(j.Close())
 			}
 		}
 	}()
-	return h.update(returning, s, t, a, v)
+	return h.update(returning, r, s, t, a, len(columns) == 0, v)
 }
 
-func (h Huge) update(returning string, s []*sql.Stmt, t *Table, a Columns, v reflect.Value) (_ interface{}, err error) {
-	now := time.Now()
+func (h Huge) update(returning string, r Columns, s []*stmt, t *Table, a Columns, track bool, v reflect.Value) (_ interface{}, err error) {
+	now := h.now()
 	var b bool
 	switch v.Kind() {
 	case reflect.Map:
 		m := reflect.MakeMap(reflect.MapOf(v.Type().Key(), typeEmpty))
 		for _, i := range v.MapKeys() {
-			b, err = h.update1(returning, s, t, a, v.MapIndex(i), now)
+			b, err = h.update1(returning, r, s, t, a, track, v.MapIndex(i), now)
 			if err != nil {
 				break
 			}
@@ -64,7 +63,7 @@ func (h Huge) update(returning string, s []*sql.Stmt, t *Table, a Columns, v ref
 		n := v.Len()
 		m := make(map[int]struct{}, n)
 		for i := 0; i < n; i++ {
-			b, err = h.update1(returning, s, t, a, v.Index(i), now)
+			b, err = h.update1(returning, r, s, t, a, track, v.Index(i), now)
 			if err != nil {
 				break
 			}
@@ -74,10 +73,13 @@ func (h Huge) update(returning string, s []*sql.Stmt, t *Table, a Columns, v ref
 		}
 		return m, err
 	}
-	return h.update1(returning, s, t, a, v, now)
+	return h.update1(returning, r, s, t, a, track, v, now)
 }
 
-func (h Huge) update1(returning string, s []*sql.Stmt, t *Table, a Columns, v reflect.Value, now time.Time) (_ bool, err error) {
+func (h Huge) update1(returning string, r Columns, s []*stmt, t *Table, a Columns, track bool, v reflect.Value, now time.Time) (ok bool, err error) {
+	if err = h.checkVersion(t); err != nil {
+		return
+	}
 	if v.Kind() == reflect.Ptr {
 		if v.IsNil() {
 			return false, t.errNil()
@@ -85,6 +87,21 @@ func (h Huge) update1(returning string, s []*sql.Stmt, t *Table, a Columns, v re
 			v = v.Elem()
 		}
 	}
+	if track {
+		if d, ok, err := t.changed(v, a); err != nil {
+			return false, err
+		} else if ok {
+			if len(d) == 0 {
+				return true, nil
+			}
+			return h.updateChanged(returning, r, t, d, v, now)
+		}
+	}
+	defer func() {
+		if ok {
+			t.takeSnapshot(v, a)
+		}
+	}()
 	b := make([]interface{}, 0, len(a)+1)
 	for _, c := range a {
 		if c.isVersion() {
@@ -107,7 +124,7 @@ func (h Huge) update1(returning string, s []*sql.Stmt, t *Table, a Columns, v re
 	j := len(p) - 1
 	b = append(b, p...)
 	if s[j] == nil {
-		set := query.X.Set()
+		update, set := h.updateSet(t.Name)
 		k := 0
 		for _, c := range a {
 			if c.isVersion() {
@@ -123,36 +140,124 @@ func (h Huge) update1(returning string, s []*sql.Stmt, t *Table, a Columns, v re
 			k++
 			where.And(t.Version().Eq(k))
 		}
-		q := query.Q(query.Update(t.Name), set, where)
-		if len(returning) > 0 {
-			q.Append(query.Literal(returning))
+		q := query.Q(update, set)
+		if len(returning) == 0 {
+			q.Append(where)
+		} else if _, ok := h.Starter.(query.Outputer); ok {
+			q.Append(query.Literal(returning), where)
+		} else {
+			q.Append(where, query.Literal(returning))
 		}
-		s[j], _, err = h.Prepare(q)
+		s[j], err = h.prepareStmt(t.Name, q)
 		if err != nil {
 			return
 		}
 	}
-	if len(returning) > 0 {
+	if len(r) > 0 {
+		d, f, err := scanColumns(r, v, h.Location)
+		if err != nil {
+			return false, err
+		}
+		if err = s[j].queryRow(b, d...); err == ErrNoRows {
+			return false, nil
+		} else if err == nil {
+			err = f()
+		}
+		if c := t.AutoNow(); err == nil && c != nil && !c.setTime(v, now, h.TimePrec) {
+			err = c.errSet()
+		}
+		return err == nil, err
+	}
+	x, err := s[j].Exec(b...)
+	if err != nil {
+		return
+	}
+	n, err := h.rowsAffected(x, 1)
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		return false, nil
+	} else if n == 1 {
 		c := t.Version()
-		k, f, ok := c.scan(v)
-		if !ok {
+		if i > 0 && !c.setInteger(v, i+1) {
 			return false, c.errSet()
 		}
-		if err = s[j].QueryRow(b...).Scan(k); err == ErrNoRows {
-			return false, nil
-		} else if err == nil && f != nil {
+		if c = t.AutoNow(); c != nil && !c.setTime(v, now, h.TimePrec) {
+			return false, c.errSet()
+		}
+		return true, nil
+	}
+	panic(fmt.Errorf("huge: RowsAffected expected 0 or 1 but was %d", n))
+}
+
+// updateChanged sets the changed columns a of v tracked by the snapshot, not prepared.
+func (h Huge) updateChanged(returning string, r Columns, t *Table, a Columns, v reflect.Value, now time.Time) (_ bool, err error) {
+	if err = h.checkVersion(t); err != nil {
+		return
+	}
+	p, i, err := t.getPrimaryKeyVersion(v)
+	if err != nil {
+		return
+	}
+	update, set := h.updateSet(t.Name)
+	for _, c := range a {
+		var k interface{}
+		if c.isVersion() {
+			k = c.Inc()
+		} else if c.isAutoNow() {
+			if k = c.convertTime(now, h.TimePrec); k == nil {
+				return false, c.errSet()
+			}
+		} else if k, err = c.get(v); err != nil {
+			return
+		}
+		set.Add(c.Name, k)
+	}
+	where := query.Where(t.PrimaryKey().Eq(p[0]))
+	if len(p) == 2 {
+		where.And(t.Version().Eq(p[1]))
+	}
+	q := query.Q(update, set)
+	if len(returning) == 0 {
+		q.Append(where)
+	} else if _, ok := h.Starter.(query.Outputer); ok {
+		q.Append(query.Literal(returning), where)
+	} else {
+		q.Append(where, query.Literal(returning))
+	}
+	if len(r) > 0 {
+		d, f, err := scanColumns(r, v, h.Location)
+		if err != nil {
+			return false, err
+		}
+		s, b, err := h.Expand(q)
+		if err != nil {
+			return false, err
+		}
+		rows, err := h.query(t.Name, s, b)
+		if err != nil {
+			return false, err
+		}
+		defer rows.Close()
+		if !rows.Next() {
+			return false, rows.Err()
+		} else if err = rows.Scan(d...); err == nil {
 			err = f()
 		}
-		if c = t.AutoNow(); err == nil && c != nil && !c.setTime(v, now, h.TimePrec) {
+		if c := t.AutoNow(); err == nil && c != nil && !c.setTime(v, now, h.TimePrec) {
 			err = c.errSet()
 		}
+		if err == nil {
+			t.takeSnapshot(v, a)
+		}
 		return err == nil, err
 	}
-	r, err := s[j].Exec(b...)
+	x, err := h.exec(t.Name, q)
 	if err != nil {
 		return
 	}
-	n, err := r.RowsAffected()
+	n, err := h.rowsAffected(x, 1)
 	if err != nil {
 		return
 	}
@@ -166,13 +271,70 @@ func (h Huge) update1(returning string, s []*sql.Stmt, t *Table, a Columns, v re
 		if c = t.AutoNow(); c != nil && !c.setTime(v, now, h.TimePrec) {
 			return false, c.errSet()
 		}
+		t.takeSnapshot(v, a)
 		return true, nil
 	}
 	panic(fmt.Errorf("huge: RowsAffected expected 0 or 1 but was %d", n))
 }
 
-// UpdateBy PK, []PK, map[PK] returns the number of rows affected by update.
+// UpdateBy PK, []PK, map[PK] returns the number of rows affected by update,
+// or submitted if the Starter can not tell, like the mutations of ClickHouse.
 func (h Huge) UpdateBy(primaryKeys, row interface{}, columns ...string) (int64, error) {
 	_, i, err := h.rud('u', primaryKeys, row, columns)
 	return i, err
 }
+
+// UpdateJSON sets the value at path of the json column of *T in place by primary key and version,
+// then the same in the column field if updated.
+func (h Huge) UpdateJSON(i interface{}, column string, value interface{}, path ...interface{}) (bool, error) {
+	t, v, w := patchRow(i)
+	c := t.Find(column)
+	if c == nil || c.isMany() {
+		panic("huge: column not found: " + column)
+	} else if f := c.last(); !f.Is(oJSON) || f.Is(oCompress) || f.Is(oEncrypt) {
+		return false, c.err("not json")
+	} else if err := h.checkVersion(t); err != nil {
+		return false, err
+	} else if err = c.setJSON(w.Elem(), value, path); err != nil {
+		return false, err
+	}
+	p, j, err := t.getPrimaryKeyVersion(v)
+	if err != nil {
+		return false, err
+	}
+	now := h.now()
+	update, set := h.updateSet(t.Name)
+	set.Add(c.Name, c.JSONSet(value, path...))
+	where := query.Where(t.PrimaryKey().Eq(p[0]))
+	if len(p) == 2 {
+		c := t.Version()
+		set.Add(c.Name, c.Inc())
+		where.And(c.Eq(p[1]))
+	}
+	if c := t.AutoNow(); c != nil {
+		k := c.convertTime(now, h.TimePrec)
+		if k == nil {
+			return false, c.errSet()
+		}
+		set.Add(c.Name, k)
+	}
+	r, err := h.exec(t.Name, query.Q(update, set, where))
+	if err != nil {
+		return false, err
+	}
+	n, err := h.rowsAffected(r, 1)
+	if err != nil || n == 0 {
+		return false, err
+	} else if n == 1 {
+		c := t.Version()
+		if j > 0 && !c.setInteger(w.Elem(), j+1) {
+			return false, c.errSet()
+		}
+		if c = t.AutoNow(); c != nil && !c.setTime(w.Elem(), now, h.TimePrec) {
+			return false, c.errSet()
+		}
+		v.Set(w.Elem())
+		return true, nil
+	}
+	panic(fmt.Errorf("huge: RowsAffected expected 0 or 1 but was %d", n))
+}
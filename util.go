@@ -5,6 +5,7 @@
 package huge
 
 import (
+	"math"
 	"reflect"
 	"time"
 )
@@ -30,6 +31,24 @@ func isIntegers(t reflect.Type) bool {
 	return isInts(k) || isUints(k)
 }
 
+// convertible v to t without changing the value, integers or strings like an untyped constant.
+func convertible(v reflect.Value, t reflect.Type) bool {
+	z := reflect.Zero(t)
+	switch k := v.Kind(); {
+	case isInts(k) && isInts(t.Kind()):
+		return !z.OverflowInt(v.Int())
+	case isInts(k) && isUints(t.Kind()):
+		return v.Int() >= 0 && !z.OverflowUint(uint64(v.Int()))
+	case isUints(k) && isUints(t.Kind()):
+		return !z.OverflowUint(v.Uint())
+	case isUints(k) && isInts(t.Kind()):
+		return v.Uint() <= math.MaxInt64 && !z.OverflowInt(int64(v.Uint()))
+	case k == reflect.String:
+		return isString(t)
+	}
+	return false
+}
+
 func isSeconds(k reflect.Kind) bool {
 	switch k {
 	case reflect.Int, reflect.Int32, reflect.Uint, reflect.Uint32:
@@ -47,6 +66,30 @@ func isTimes(t reflect.Type) bool {
 	return isSeconds(k) || isMilliseconds(k) || t == typeTime
 }
 
+func isString(t reflect.Type) bool {
+	return t.Kind() == reflect.String
+}
+
+func isDateOrTime(t reflect.Type) bool {
+	return t == typeDate || t == typeTime
+}
+
+func isTime(t reflect.Type) bool {
+	return t == typeTime
+}
+
+func isStringOrBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8)
+}
+
+func isDecimals(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return t == typeDecimal
+}
+
 func isStruct(t reflect.Type) bool {
 	return t.Kind() == reflect.Struct
 }